@@ -1,5 +1,14 @@
 package burpsuite
 
+import (
+	"sort"
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/fingerprint"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/js"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/secrets"
+)
+
 /*
 Load Items From Burpsuite Schema and
 Structure them into schema similar to sitemap tree
@@ -26,3 +35,78 @@ func NewSiteMap(burp Items) *SiteMap {
 
 	return &x
 }
+
+// JSEndpoints : Extract endpoints & parameters from all javascript
+// and html (inline scripts) responses of sitemap
+func (s *SiteMap) JSEndpoints() *js.Result {
+	result := js.Extract(nil, "")
+
+	for _, v := range s.AllItems {
+		if v.Response == nil || len(v.Response.Body) == 0 {
+			continue
+		}
+		if js.IsJavaScript(v.Response.ContentType, v.URL) || strings.Contains(v.Response.ContentType, "html") {
+			result.Merge(js.Extract(v.Response.Body, v.URL))
+		}
+	}
+
+	return result
+}
+
+// Secrets : Scan all responses of sitemap for secrets
+func (s *SiteMap) Secrets(scanner *secrets.Scanner) []secrets.Finding {
+	findings := []secrets.Finding{}
+
+	for _, v := range s.AllItems {
+		if v.Response == nil {
+			continue
+		}
+		for _, f := range scanner.ScanResponse(v.Response) {
+			f.Source = v.URL
+			findings = append(findings, f)
+		}
+	}
+
+	return findings
+}
+
+// Technologies : Fingerprint technologies of every domain in sitemap
+// Matches of all responses of a domain are merged (highest confidence and longest version is kept)
+func (s *SiteMap) Technologies(engine *fingerprint.Engine) map[string][]fingerprint.Match {
+	merged := map[string]map[string]fingerprint.Match{}
+
+	for _, v := range s.AllItems {
+		if v.Response == nil {
+			continue
+		}
+		if merged[v.DomainName] == nil {
+			merged[v.DomainName] = map[string]fingerprint.Match{}
+		}
+		for _, m := range engine.AnalyzeResponse(v.Response) {
+			old, ok := merged[v.DomainName][m.Name]
+			if ok {
+				if old.Confidence > m.Confidence {
+					m.Confidence = old.Confidence
+				}
+				if len(old.Version) > len(m.Version) {
+					m.Version = old.Version
+				}
+				if old.ImpliedBy == "" {
+					m.ImpliedBy = ""
+				}
+			}
+			merged[v.DomainName][m.Name] = m
+		}
+	}
+
+	result := map[string][]fingerprint.Match{}
+	for domain, matches := range merged {
+		result[domain] = []fingerprint.Match{}
+		for _, m := range matches {
+			result[domain] = append(result[domain], m)
+		}
+		sort.Slice(result[domain], func(i, j int) bool { return result[domain][i].Name < result[domain][j].Name })
+	}
+
+	return result
+}
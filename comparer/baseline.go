@@ -0,0 +1,321 @@
+package comparer
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/comparer/diff"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+/*
+Baseline Learning
+
+Targets are not always deterministic . Same request can return
+different headers ,cookies ,ids or ads in body .Comparing against a
+single response reports these as changes (false positives)
+
+Baseline learns from N responses of same request
+1. Headers/Cookies not present in all samples are removed before comparison
+2. Headers/Cookies whose value differs between samples are masked
+3. Changed lines in body/location are converted to rules
+   (common start and end of line is kept and changed part is masked)
+   and added to normalizer . Lines which only share markup are not converted
+4. Factors which still differ after above steps are ignored
+5. Body threshold is lowered to minimum similarity between samples
+
+Later responses are compared with first sample using learned settings
+*/
+
+// BaselineMargin : Learned body threshold is this much lower than minimum similarity of samples
+var BaselineMargin = 0.02
+
+// MaxLearnedRules : Maximum number of rules learned from body changes
+var MaxLearnedRules = 100
+
+// MinRuleAnchor : Minimum number of word characters (outside html tags) in common
+// start and end of changed lines for a rule to be learned
+// (ex: <div>a</div> & <div>b</div> only share markup and would mask every div)
+var MinRuleAnchor = 4
+
+var markupRegex = regexp.MustCompile(`<[^>]*>?`)
+
+// Baseline : Learned stable state of responses to a request
+type Baseline struct {
+	Samples    []*rawhttp.RawHttpResponse // Responses to same request (first sample is reference)
+	Normalizer *Normalizer                // Optional normalizer applied before learning
+
+	// Learned Values
+	Unstable      map[Factor]bool // Factors which differ between samples (ignored)
+	Headers       map[string]bool // Headers not present in all samples (canonical)
+	HeaderValues  map[string]bool // Headers whose value differs between samples (canonical)
+	Cookies       map[string]bool // Cookies not present in all samples
+	CookieValues  map[string]bool // Cookies whose value differs between samples
+	Rules         []Rule          // Rules learned from changes in body and location
+	Similarity    float64         // Minimum body similarity between samples
+	BodyThreshold float64         // Body threshold used for comparison
+
+	normalizer *Normalizer              // normalizer with learned rules
+	reference  *rawhttp.RawHttpResponse // prepared first sample
+}
+
+// Learn : Learn unstable parts from samples (at least 2 samples are required)
+func (b *Baseline) Learn() error {
+	if len(b.Samples) < 2 {
+		return fmt.Errorf("at least 2 samples are required to learn baseline")
+	}
+	for _, v := range b.Samples {
+		if v == nil {
+			return fmt.Errorf("missing sample response")
+		}
+	}
+
+	b.Unstable = map[Factor]bool{}
+	b.Headers = map[string]bool{}
+	b.HeaderValues = map[string]bool{}
+	b.Cookies = map[string]bool{}
+	b.CookieValues = map[string]bool{}
+	b.Rules = []Rule{}
+
+	// 1. headers & cookies
+	headers := map[string]int{}
+	values := map[string]string{}
+	cookies := map[string]int{}
+	cookievalues := map[string]string{}
+	for _, s := range b.Samples {
+		for k, v := range s.Headers {
+			headers[k]++
+			value := strings.Join(v, "\n")
+			if old, ok := values[k]; ok && old != value {
+				b.HeaderValues[k] = true
+			}
+			values[k] = value
+		}
+		for k, v := range s.Cookies {
+			cookies[k]++
+			if old, ok := cookievalues[k]; ok && old != v.Value {
+				b.CookieValues[k] = true
+			}
+			cookievalues[k] = v.Value
+		}
+	}
+	for k, count := range headers {
+		if count != len(b.Samples) {
+			b.Headers[k] = true
+		}
+	}
+	for k, count := range cookies {
+		if count != len(b.Samples) {
+			b.Cookies[k] = true
+		}
+	}
+
+	// 2. rules from body & location changes
+	b.normalizer = &Normalizer{}
+	if b.Normalizer != nil {
+		b.normalizer = b.Normalizer.Clone()
+	}
+	learned := map[string]bool{}
+	learn := func(old []byte, new []byte) {
+		for _, r := range learnRules(old, new) {
+			if !learned[r.Regex.String()] && len(b.Rules) < MaxLearnedRules {
+				learned[r.Regex.String()] = true
+				b.Rules = append(b.Rules, r)
+			}
+		}
+	}
+	first := b.normalizer.NormalizeResponse(b.Samples[0])
+	for _, s := range b.Samples[1:] {
+		other := b.normalizer.NormalizeResponse(s)
+		learn(first.Body, other.Body)
+		learn([]byte(first.Location), []byte(other.Location))
+	}
+	b.normalizer.Rules = append(b.normalizer.Rules, b.Rules...)
+
+	// 3. factors which are still unstable
+	b.reference = b.prepare(b.Samples[0])
+	b.Similarity = 1
+	for _, s := range b.Samples[1:] {
+		other := b.prepare(s)
+		if score := Similarity(b.reference.Body, other.Body); score < b.Similarity {
+			b.Similarity = score
+		}
+
+		d := &DualResponseComparer{
+			Old:        b.reference,
+			New:        other,
+			Ignore:     map[Factor]bool{Body: true},
+			Exclusions: DefaultExclusions(),
+		}
+		changes, _ := d.Compare()
+		for _, c := range changes {
+			b.Unstable[c.Type] = true
+		}
+	}
+
+	b.BodyThreshold = DefaultBodyThreshold
+	if b.Similarity-BaselineMargin < b.BodyThreshold {
+		b.BodyThreshold = b.Similarity - BaselineMargin
+	}
+	if b.BodyThreshold <= 0 {
+		// bodies have nothing in common
+		b.Unstable[Body] = true
+	}
+
+	return nil
+}
+
+// IsStable : Check if all samples are same
+func (b *Baseline) IsStable() bool {
+	return len(b.Unstable) == 0 && len(b.Headers) == 0 && len(b.HeaderValues) == 0 &&
+		len(b.Cookies) == 0 && len(b.CookieValues) == 0 && len(b.Rules) == 0 && b.Similarity == 1
+}
+
+// Comparer : Comparer between reference sample and new response using learned settings
+// Only unstable factors are ignored by returned comparer
+func (b *Baseline) Comparer(new *rawhttp.RawHttpResponse) *DualResponseComparer {
+	d := &DualResponseComparer{
+		Old:           b.reference,
+		New:           b.prepare(new),
+		Ignore:        map[Factor]bool{},
+		BodyThreshold: b.BodyThreshold,
+		Exclusions:    DefaultExclusions(),
+	}
+	for k, v := range b.Unstable {
+		d.Ignore[k] = v
+	}
+	return d
+}
+
+// Compare : Compare new response with baseline
+func (b *Baseline) Compare(new *rawhttp.RawHttpResponse) ([]Change, error) {
+	if b.reference == nil {
+		return nil, fmt.Errorf("baseline is not learned")
+	}
+	if new == nil {
+		return nil, fmt.Errorf("missing Responses to Compare")
+	}
+	return b.Comparer(new).Compare()
+}
+
+// prepare : normalized copy of response without unstable headers and cookies
+func (b *Baseline) prepare(resp *rawhttp.RawHttpResponse) *rawhttp.RawHttpResponse {
+	if resp == nil {
+		return nil
+	}
+	prepared := b.normalizer.NormalizeResponse(resp)
+
+	headers := http.Header{}
+	for k, v := range prepared.Headers {
+		switch {
+		case b.Headers[k]:
+		case b.HeaderValues[k]:
+			headers[k] = []string{"{{unstable}}"}
+		default:
+			headers[k] = v
+		}
+	}
+	prepared.Headers = headers
+
+	cookies := map[string]*http.Cookie{}
+	for k, v := range prepared.Cookies {
+		switch {
+		case b.Cookies[k]:
+		case b.CookieValues[k]:
+			masked := *v
+			masked.Value = "{{unstable}}"
+			cookies[k] = &masked
+		default:
+			cookies[k] = v
+		}
+	}
+	prepared.Cookies = cookies
+
+	return prepared
+}
+
+// learnRules : rules matching changed lines between old and new
+func learnRules(old []byte, new []byte) []Rule {
+	rules := []Rule{}
+	edits := diff.Lines(old, new, nil)
+
+	for i := 0; i < len(edits); {
+		if edits[i].Op == diff.Equal {
+			i++
+			continue
+		}
+		// deleted lines are followed by inserted lines
+		deleted := []string{}
+		for i < len(edits) && edits[i].Op == diff.Delete {
+			deleted = append(deleted, edits[i].Text)
+			i++
+		}
+		inserted := []string{}
+		for i < len(edits) && edits[i].Op == diff.Insert {
+			inserted = append(inserted, edits[i].Text)
+			i++
+		}
+		for j := 0; j < len(deleted) && j < len(inserted); j++ {
+			if r, ok := learnRule(deleted[j], inserted[j]); ok {
+				rules = append(rules, r)
+			}
+		}
+	}
+
+	return rules
+}
+
+// learnRule : rule with common start and end of lines (changed part is masked)
+func learnRule(a string, b string) (Rule, bool) {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	// changed part always starts and ends at word boundary
+	// (ex: count: 19 & count: 10 => count: {{dynamic}})
+	start, end := a[:prefix], a[len(a)-suffix:]
+	start = strings.TrimRightFunc(start, isWordChar)
+	end = strings.TrimLeftFunc(end, isWordChar)
+
+	if anchorLength(start)+anchorLength(end) < MinRuleAnchor {
+		// whole line is changed or lines only share markup
+		return Rule{}, false
+	}
+
+	pattern := `(?m)^` + regexp.QuoteMeta(start) + `([^\n]*?)` + regexp.QuoteMeta(end) + `\r?$`
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return Rule{}, false
+	}
+	return Rule{Name: "dynamic", Regex: re}, true
+}
+
+// anchorLength : number of word characters outside html tags
+func anchorLength(s string) int {
+	count := 0
+	for _, r := range markupRegex.ReplaceAllString(s, "") {
+		if isWordChar(r) {
+			count++
+		}
+	}
+	return count
+}
+
+func isWordChar(r rune) bool {
+	return r == '_' || r == '-' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9')
+}
+
+// NewBaseline : Learn baseline from samples
+func NewBaseline(samples ...*rawhttp.RawHttpResponse) (*Baseline, error) {
+	b := &Baseline{
+		Samples: samples,
+	}
+	return b, b.Learn()
+}
@@ -0,0 +1,109 @@
+package comparer_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/comparer"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+func Test_Baseline(t *testing.T) {
+	sample := func(i int, extra string) *rawhttp.RawHttpResponse {
+		raw := "HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n"
+		raw += fmt.Sprintf("X-Served-By: cache-%v\r\n", i)
+		if i%2 == 0 {
+			raw += "X-Cache: HIT\r\n"
+			raw += fmt.Sprintf("Set-Cookie: lb=%v\r\n", i)
+		}
+		body := "<html>\n<h1>Products</h1>\n"
+		body += fmt.Sprintf("<p>You are visitor number %v today</p>\n", 1000+i*7)
+		body += fmt.Sprintf("<div class=\"ad\">%v</div>\n", []string{"buy shoes", "cheap flights", "new phones"}[i%3])
+		body += extra + "</html>\n"
+
+		resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte(raw + "\r\n" + body))
+		if err != nil {
+			t.Fatalf("failed to parse response %v", err)
+		}
+		return resp
+	}
+
+	if _, err := comparer.NewBaseline(sample(0, "")); err == nil {
+		t.Errorf("expected error with single sample")
+	}
+
+	b, err := comparer.NewBaseline(sample(0, ""), sample(1, ""), sample(2, ""), sample(3, ""))
+	if err != nil {
+		t.Fatalf("failed to learn baseline %v", err)
+	}
+
+	if !b.Headers["X-Cache"] || !b.HeaderValues["X-Served-By"] || !b.Cookies["lb"] || b.IsStable() {
+		t.Errorf("unstable headers/cookies not learned %v %v %v", b.Headers, b.HeaderValues, b.Cookies)
+	}
+	if len(b.Rules) == 0 {
+		t.Errorf("no rules learned from body")
+	}
+
+	// naive comparison reports changes
+	naive := comparer.NewDualResponseComparer(sample(0, ""), sample(5, ""))
+	naive.Ignore = map[comparer.Factor]bool{}
+	if changes, _ := naive.Compare(); len(changes) == 0 {
+		t.Fatalf("expected changes without baseline")
+	}
+
+	// same page is stable
+	if changes, err := b.Compare(sample(5, "")); err != nil || len(changes) != 0 {
+		t.Errorf("expected no changes got %v %v", changes, err)
+	}
+
+	// real change is still reported
+	changes, _ := b.Compare(sample(4, "<p>Debug mode enabled , stacktrace follows</p>\n<pre>at main.go:10</pre>\n"))
+	found := false
+	for _, c := range changes {
+		if c.Type == comparer.Body {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected body change got %v", changes)
+	}
+
+	// one2many comparer uses baseline
+	m := comparer.NewOne2ManyResponseComparer(nil, sample(6, ""), sample(7, ""), sample(8, "<p>admin</p>\n"))
+	m.Ignore = map[comparer.Factor]bool{}
+	m.Baseline = b
+	if res := m.Compare(context.Background()); len(res) != 1 {
+		t.Errorf("expected 1 changed response got %v", res)
+	}
+}
+
+func Test_BaselineMarkupRules(t *testing.T) {
+	sample := func(ad string, count int) *rawhttp.RawHttpResponse {
+		body := fmt.Sprintf("<html>\n<div>%v</div>\n<p>visitors: %v</p>\n<div>admin panel</div>\n</html>\n", ad, count)
+		resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n" + body))
+		if err != nil {
+			t.Fatalf("failed to parse response %v", err)
+		}
+		return resp
+	}
+
+	b, err := comparer.NewBaseline(sample("buy shoes", 10), sample("cheap flights", 11))
+	if err != nil {
+		t.Fatalf("failed to learn baseline %v", err)
+	}
+
+	// lines only sharing tags must not be converted to rules
+	learned := false
+	for _, r := range b.Rules {
+		if r.Regex.MatchString("<div>admin panel</div>") {
+			t.Errorf("rule %v masks unrelated lines", r.Regex)
+		}
+		if r.Regex.MatchString("<p>visitors: 99</p>") {
+			learned = true
+		}
+	}
+	if !learned {
+		t.Errorf("rule with text anchor not learned %v", b.Rules)
+	}
+}
@@ -0,0 +1,231 @@
+package comparer
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+/*
+Response Clustering
+
+Groups large number of responses (ex: fuzzing results) into clusters
+of similar responses so that rare outliers stand out
+
+Responses are in same cluster if they have
+1. Same status code ,content type and set of header names
+2. Body length ,word count and line count within LengthTolerance of representative
+3. Body similarity with representative >= Threshold
+
+First response of a cluster is its representative . Representative of
+every cluster is compared with representative of largest cluster
+(many-to-many analysis) to show how it is different
+*/
+
+// DefaultClusterThreshold : Minimum body similarity of responses in same cluster
+// (lower than DefaultBodyThreshold since small pages with a reflected value are less similar)
+var DefaultClusterThreshold = 0.8
+
+// Cluster : Group of similar responses
+type Cluster struct {
+	ID             int
+	Representative *rawhttp.RawHttpResponse
+	Members        []*rawhttp.RawHttpResponse // All responses in cluster (including representative)
+	Indexes        []int                      // Index of each member in input
+	StatusCode     int
+	ContentType    string
+	Headers        []string // Header names of representative (sorted)
+	Length         int      // Body length of representative
+	Words          int      // Word count of representative
+	Lines          int      // Line count of representative
+	Changes        []Change // Changes compared to representative of largest cluster
+
+	tokens map[string]int
+}
+
+// Count : Number of responses in cluster
+func (c *Cluster) Count() int {
+	return len(c.Members)
+}
+
+// String : Summary of cluster
+func (c *Cluster) String() string {
+	return fmt.Sprintf("#%v status=%v type=%v length=%v words=%v lines=%v count=%v", c.ID, c.StatusCode, c.ContentType, c.Length, c.Words, c.Lines, c.Count())
+}
+
+// Clusterer : Group responses into clusters
+type Clusterer struct {
+	Threshold       float64         // Minimum body similarity with representative (Default: DefaultClusterThreshold)
+	LengthTolerance float64         // Max relative difference of length ,words and lines (Default: 0.1)
+	Normalizer      *Normalizer     // If not nil dynamic content is masked before clustering
+	Ignore          map[Factor]bool /* Factors Ignored While comparing representatives
+	By default DefaultIgnore() except Body are ignored */
+	Exclusions *Exclusions // Excluded keys (excluded headers are not part of header set)
+}
+
+// Cluster : Group responses into clusters (largest cluster first)
+// nil responses are skipped
+func (c *Clusterer) Cluster(responses ...*rawhttp.RawHttpResponse) []*Cluster {
+	threshold := c.Threshold
+	if threshold <= 0 {
+		threshold = DefaultClusterThreshold
+	}
+	tolerance := c.LengthTolerance
+	if tolerance <= 0 {
+		tolerance = 0.1
+	}
+
+	clusters := []*Cluster{}
+	groups := map[string][]*Cluster{}
+
+	for i, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		original := resp
+		if c.Normalizer != nil {
+			resp = c.Normalizer.NormalizeResponse(resp)
+		}
+
+		headers := headerNames(resp.Headers, c.Exclusions)
+		key := fmt.Sprintf("%v|%v|%v", resp.StatusCode, resp.ContentType, strings.Join(headers, ","))
+		length, words, lines := len(resp.Body), wordCount(resp.Body), lineCount(resp.Body)
+		tokens := tokenCounts(resp.Body)
+
+		var found *Cluster
+		for _, cl := range groups[key] {
+			if !withinTolerance(cl.Length, length, tolerance) || !withinTolerance(cl.Words, words, tolerance) || !withinTolerance(cl.Lines, lines, tolerance) {
+				continue
+			}
+			if weightedJaccard(cl.tokens, tokens) >= threshold {
+				found = cl
+				break
+			}
+		}
+
+		if found == nil {
+			found = &Cluster{
+				ID:             len(clusters) + 1,
+				Representative: original,
+				StatusCode:     resp.StatusCode,
+				ContentType:    resp.ContentType,
+				Headers:        headers,
+				Length:         length,
+				Words:          words,
+				Lines:          lines,
+				tokens:         tokens,
+			}
+			clusters = append(clusters, found)
+			groups[key] = append(groups[key], found)
+		}
+		found.Members = append(found.Members, original)
+		found.Indexes = append(found.Indexes, i)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Count() > clusters[j].Count()
+	})
+
+	// compare representatives with largest cluster
+	if len(clusters) > 0 {
+		ignore := c.Ignore
+		if ignore == nil {
+			ignore = DefaultIgnore()
+			delete(ignore, Body)
+		}
+		for _, cl := range clusters[1:] {
+			d := &DualResponseComparer{
+				Old:           clusters[0].Representative,
+				New:           cl.Representative,
+				Ignore:        ignore,
+				BodyThreshold: threshold,
+				Normalizer:    c.Normalizer,
+				Exclusions:    c.Exclusions,
+			}
+			cl.Changes, _ = d.Compare()
+		}
+	}
+
+	return clusters
+}
+
+// Outliers : Clusters with at most max responses (smallest first)
+func Outliers(clusters []*Cluster, max int) []*Cluster {
+	outliers := []*Cluster{}
+	for _, cl := range clusters {
+		if cl.Count() <= max {
+			outliers = append(outliers, cl)
+		}
+	}
+	sort.SliceStable(outliers, func(i, j int) bool {
+		return outliers[i].Count() < outliers[j].Count()
+	})
+	return outliers
+}
+
+// ClusterResults : Group results of One2ManyResponseComparer
+// Indexes of clusters are One2ManyResults.Index (not position in results)
+// so that they point to Many or stream order even if results are filtered
+func ClusterResults(results []One2ManyResults) []*Cluster {
+	responses := []*rawhttp.RawHttpResponse{}
+	for _, v := range results {
+		responses = append(responses, v.Resp)
+	}
+	clusters := NewClusterer().Cluster(responses...)
+	for _, cl := range clusters {
+		for i, pos := range cl.Indexes {
+			cl.Indexes[i] = results[pos].Index
+		}
+	}
+	return clusters
+}
+
+// headerNames : sorted header names (excluded headers are skipped)
+func headerNames(headers http.Header, exclusions *Exclusions) []string {
+	names := []string{}
+	for k, v := range headers {
+		if !exclusions.Excluded(Header, k, strings.Join(v, ", ")) {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withinTolerance : relative difference of a and b is at most tolerance
+func withinTolerance(a int, b int, tolerance float64) bool {
+	diff, max := a-b, a
+	if diff < 0 {
+		diff, max = -diff, b
+	}
+	return diff == 0 || float64(diff) <= tolerance*float64(max)
+}
+
+// wordCount : number of whitespace separated words
+func wordCount(data []byte) int {
+	return len(bytes.Fields(data))
+}
+
+// lineCount : number of lines
+func lineCount(data []byte) int {
+	if len(data) == 0 {
+		return 0
+	}
+	return bytes.Count(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")) + 1
+}
+
+// NewClusterer : Clusterer with default settings
+func NewClusterer() *Clusterer {
+	ignore := DefaultIgnore()
+	delete(ignore, Body)
+	return &Clusterer{
+		Threshold:       DefaultClusterThreshold,
+		LengthTolerance: 0.1,
+		Ignore:          ignore,
+		Exclusions:      DefaultExclusions(),
+	}
+}
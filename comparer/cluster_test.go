@@ -0,0 +1,93 @@
+package comparer_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/comparer"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+func Test_Cluster(t *testing.T) {
+	parse := func(status string, body string) *rawhttp.RawHttpResponse {
+		resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 " + status + "\r\nContent-Type: text/html\r\n\r\n" + body))
+		if err != nil {
+			t.Fatalf("failed to parse response %v", err)
+		}
+		return resp
+	}
+
+	responses := []*rawhttp.RawHttpResponse{}
+	for i := 0; i < 50; i++ {
+		// not found pages with reflected path
+		responses = append(responses, parse("404 Not Found", fmt.Sprintf("<html>\n<h1>Not Found</h1>\n<p>The page /admin%v was not found on this server</p>\n</html>\n", i)))
+	}
+	for i := 0; i < 10; i++ {
+		responses = append(responses, parse("403 Forbidden", "<html>\n<h1>Forbidden</h1>\n</html>\n"))
+	}
+	responses = append(responses, nil)
+	responses = append(responses, parse("200 OK", "<html>\n<h1>Admin Panel</h1>\n<p>Welcome back administrator</p>\n<ul><li>users</li><li>settings</li></ul>\n</html>\n"))
+	responses = append(responses, parse("404 Not Found", "<html>\n<h1>Not Found</h1>\n<p>Backup file exists but download is disabled , contact your administrator to enable backups</p>\n</html>\n"))
+
+	clusters := comparer.NewClusterer().Cluster(responses...)
+	if len(clusters) != 4 {
+		for _, v := range clusters {
+			t.Log(v)
+		}
+		t.Fatalf("expected 4 clusters got %v", len(clusters))
+	}
+
+	if clusters[0].Count() != 50 || clusters[0].StatusCode != 404 || clusters[1].Count() != 10 {
+		t.Errorf("unexpected clusters %v %v", clusters[0], clusters[1])
+	}
+
+	outliers := comparer.Outliers(clusters, 1)
+	if len(outliers) != 2 || outliers[0].Indexes[0] != 61 || outliers[1].Indexes[0] != 62 {
+		t.Errorf("unexpected outliers %v", outliers)
+	}
+
+	// representatives are compared with largest cluster
+	for _, v := range outliers {
+		if len(v.Changes) == 0 {
+			t.Errorf("expected changes for %v", v)
+		}
+	}
+	if len(clusters[0].Changes) != 0 {
+		t.Errorf("largest cluster must not have changes")
+	}
+}
+
+func Test_ClusterResults(t *testing.T) {
+	parse := func(status string, body string) *rawhttp.RawHttpResponse {
+		resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 " + status + "\r\nContent-Type: text/html\r\n\r\n" + body))
+		if err != nil {
+			t.Fatalf("failed to parse response %v", err)
+		}
+		return resp
+	}
+	home := "<html>\n<h1>Home</h1>\n<p>Welcome to our website</p>\n</html>\n"
+	forbidden := "<html>\n<h1>Forbidden</h1>\n</html>\n"
+
+	m := comparer.NewOne2ManyResponseComparer(parse("200 OK", home),
+		parse("200 OK", home),
+		parse("403 Forbidden", forbidden),
+		parse("200 OK", home),
+		parse("403 Forbidden", forbidden),
+		parse("500 Internal Server Error", "<html>\n<h1>Error</h1>\n<p>stacktrace follows</p>\n</html>\n"),
+	)
+
+	// unchanged responses are not part of results
+	results := m.Compare(context.Background())
+	if len(results) != 3 {
+		t.Fatalf("expected 3 changed results got %v", len(results))
+	}
+
+	clusters := comparer.ClusterResults(results)
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters got %v", len(clusters))
+	}
+	if fmt.Sprint(clusters[0].Indexes) != "[1 3]" || fmt.Sprint(clusters[1].Indexes) != "[4]" {
+		t.Errorf("indexes must point to Many got %v %v", clusters[0].Indexes, clusters[1].Indexes)
+	}
+}
@@ -1,11 +1,19 @@
 package comparer
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/tarunKoyalwar/goseclibs/comparer/diff"
 	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/html"
 )
 
 /*
@@ -19,12 +27,18 @@ and other commons like statuscode ,bodylength
 
 */
 
-// Exclusions For any given type of Factor
-// StatusCode, ContentType, ContentLength does not account for
-// exclusions as it doesn't make any sense
-// exclusions with empty arr will skip the factor entirely
-var Exclusions map[Factor][]string = map[Factor][]string{
-	Header: {"date"},
+// DefaultBodyThreshold : Body change is reported if similarity is below this value
+var DefaultBodyThreshold = 0.95
+
+// DefaultTimingTolerance : Timing change is reported if difference of response times exceeds this value
+var DefaultTimingTolerance = 2 * time.Second
+
+// Tolerance : Allowed difference of numeric factors (change is reported only if difference is larger)
+type Tolerance struct {
+	ContentLength int           // ±bytes
+	Words         int           // ±words
+	Lines         int           // ±lines
+	Timing        time.Duration // Difference of response time (Default: DefaultTimingTolerance)
 }
 
 // DualResponseComparer : Compare any two responses and get changes
@@ -32,19 +46,34 @@ type DualResponseComparer struct {
 	Old    *rawhttp.RawHttpResponse
 	New    *rawhttp.RawHttpResponse
 	Ignore map[Factor]bool /* These Factors are Ignored and are not calculated
-	By default all Factors are considered except HeaderValue*/
+	By default all Factors are considered except DefaultIgnore() (HeaderValue ,CookieValue ,JSONKey ,Body and content metrics)*/
+	BodyThreshold float64          // Minimum similarity of bodies (Default: DefaultBodyThreshold)
+	DiffOptions   *diff.Options    // If not nil unified diff of bodies is added to Body change
+	Normalizer    *Normalizer      // If not nil dynamic content is masked before comparison
+	Exclusions    *Exclusions      // Excluded keys of factors (Default: DefaultExclusions() ,nil => nothing excluded)
+	Tolerance     Tolerance        // Allowed difference of ContentLength ,Words ,Lines and Timing
+	Custom        []FactorComparer // Custom factors compared after builtin factors (use FactorOf() to ignore)
 }
 
 // Compare : This Function return Changes (Empty array is returned if there are no differences/changes)
 func (d *DualResponseComparer) Compare() ([]Change, error) {
 	changes := []Change{}
 
-	AllFactors := []Factor{StatusCode, ContentLength, ContentType, Header, HeaderValue, Cookie, Location}
+	AllFactors := []Factor{StatusCode, ContentLength, ContentType, Header, HeaderValue, Cookie, CookieValue, Location, RedirectHost, JSONKey, Body, BodyHash, Words, Lines, Title, Timing}
 
 	if d.Old == nil || d.New == nil {
 		return changes, fmt.Errorf("missing Responses to Compare")
 	}
 
+	if d.Normalizer != nil {
+		// compare normalized copies
+		n := *d
+		n.Old = d.Normalizer.NormalizeResponse(d.Old)
+		n.New = d.Normalizer.NormalizeResponse(d.New)
+		n.Normalizer = nil
+		return n.Compare()
+	}
+
 	for _, v := range AllFactors {
 
 		if d.Ignore[v] {
@@ -52,13 +81,6 @@ func (d *DualResponseComparer) Compare() ([]Change, error) {
 			continue
 		}
 
-		w, ok := Exclusions[v]
-		if ok {
-			if w == nil || len(w) == 0 {
-				continue
-			}
-		}
-
 		switch v {
 		case StatusCode:
 			// Check For Changes in Status Code
@@ -73,7 +95,7 @@ func (d *DualResponseComparer) Compare() ([]Change, error) {
 
 		case ContentLength:
 			// Check For Changes in ContentLength
-			if d.Old.ContentLength != d.New.ContentLength {
+			if abs(d.Old.ContentLength-d.New.ContentLength) > d.Tolerance.ContentLength {
 				clc := Change{
 					Type: ContentLength,
 					Old:  strconv.Itoa(d.Old.ContentLength),
@@ -126,184 +148,353 @@ func (d *DualResponseComparer) Compare() ([]Change, error) {
 				changes = append(changes, *cc)
 			}
 
+		case CookieValue:
+			cvc := d.compareCookieValues()
+			if cvc != nil {
+				changes = append(changes, *cvc)
+			}
+
+		case JSONKey:
+			jc := d.compareJSONKeys()
+			if jc != nil {
+				changes = append(changes, *jc)
+			}
+
+		case Body:
+			bc := d.compareBody()
+			if bc != nil {
+				changes = append(changes, *bc)
+			}
+
+		case RedirectHost:
+			oldhost, newhost := redirectHost(d.Old.Location), redirectHost(d.New.Location)
+			if oldhost != newhost {
+				changes = append(changes, Change{
+					Type: RedirectHost,
+					Old:  oldhost,
+					New:  newhost,
+				})
+			}
+
+		case BodyHash:
+			oldhash, newhash := bodyHash(d.Exclusions.Remove(d.Old.Body)), bodyHash(d.Exclusions.Remove(d.New.Body))
+			if oldhash != newhash {
+				changes = append(changes, Change{
+					Type: BodyHash,
+					Old:  oldhash,
+					New:  newhash,
+				})
+			}
+
+		case Words:
+			oldwords, newwords := wordCount(d.Exclusions.Remove(d.Old.Body)), wordCount(d.Exclusions.Remove(d.New.Body))
+			if abs(oldwords-newwords) > d.Tolerance.Words {
+				changes = append(changes, Change{
+					Type: Words,
+					Old:  strconv.Itoa(oldwords),
+					New:  strconv.Itoa(newwords),
+				})
+			}
+
+		case Lines:
+			oldlines, newlines := lineCount(d.Exclusions.Remove(d.Old.Body)), lineCount(d.Exclusions.Remove(d.New.Body))
+			if abs(oldlines-newlines) > d.Tolerance.Lines {
+				changes = append(changes, Change{
+					Type: Lines,
+					Old:  strconv.Itoa(oldlines),
+					New:  strconv.Itoa(newlines),
+				})
+			}
+
+		case Title:
+			oldtitle, newtitle := pageTitle(d.Old), pageTitle(d.New)
+			if oldtitle != newtitle {
+				changes = append(changes, Change{
+					Type: Title,
+					Old:  oldtitle,
+					New:  newtitle,
+				})
+			}
+
+		case Timing:
+			tc := d.compareTiming()
+			if tc != nil {
+				changes = append(changes, *tc)
+			}
+
 		}
 
 	}
 
 	// After Comparison of All Factors
+	changes = append(changes, d.compareCustom()...)
 
 	return changes, nil
 
 }
 
 func (d *DualResponseComparer) compareHeaders() *Change {
-	excluded := map[string]bool{}
-	if w, ok := Exclusions[Header]; ok {
-		for _, v := range w {
-			excluded[v] = true
-		}
-	}
 	// Change If any header was added /removed
-	// Unique map of old headers
-	oldunique := map[string]bool{}
-	for k, _ := range d.Old.Headers {
-		// check if this header is excluded
-		if w, ok := excluded[k]; ok && w {
-		} else {
-			oldunique[k] = true
-		}
-	}
-	found := ""
-
-	newunique := map[string]bool{}
-	for k, _ := range d.New.Headers {
-		// check if this header is excluded
-		if w, ok := excluded[k]; ok && w {
-		} else {
-			newunique[k] = true
-			// Check if any new request contains any additional value
-			if w, ok := oldunique[k]; !ok && !w {
-				found += k + " // Extra Header\n"
-			}
+	// values of added/removed headers are included in entries
+	c := &Change{Type: Header}
+	for _, k := range sortedHeaderKeys(d.New.Headers) {
+		if _, ok := d.Old.Headers[k]; !ok && !d.Exclusions.Excluded(Header, k, strings.Join(d.New.Headers[k], ", ")) {
+			c.Added = append(c.Added, Entry{Key: k, New: strings.Join(d.New.Headers[k], ", ")})
 		}
 	}
 	//Check if any headers are missing
-	for k, _ := range oldunique {
-		// Check if any new request contains any additional value
-		if w, ok := newunique[k]; !ok && !w {
-			found += k + " // Missing Header\n"
+	for _, k := range sortedHeaderKeys(d.Old.Headers) {
+		if _, ok := d.New.Headers[k]; !ok && !d.Exclusions.Excluded(Header, k, strings.Join(d.Old.Headers[k], ", ")) {
+			c.Removed = append(c.Removed, Entry{Key: k, Old: strings.Join(d.Old.Headers[k], ", ")})
 		}
 	}
 
-	if found != "" {
-		// Changes found
-		hc := Change{
-			Type: Header,
-			Old:  "", // Doesnot make sense
-			New:  found,
-		}
-		return &hc
+	if c.IsEmpty() {
+		return nil
 	}
-
-	return nil
+	return c
 }
 
 func (d *DualResponseComparer) compareHeaderValues() *Change {
 	// This comparison is unnecessary and only required in
 	// rare cases and is blacklisted by default
 
-	// Method of comparison is same only
-	// values here are in `header:value` format
-	excluded := map[string]bool{}
-	if w, ok := Exclusions[HeaderValue]; ok {
-		for _, v := range w {
-			excluded[v] = true
+	// Only headers present in both responses are compared
+	// (added/removed headers are reported by Header)
+	// repeated headers are compared value by value
+	c := &Change{Type: HeaderValue}
+	for _, k := range sortedHeaderKeys(d.New.Headers) {
+		oldvals, ok := d.Old.Headers[k]
+		if !ok {
+			continue
 		}
-	}
-	// Change If any header was added /removed
-	// Unique map of old headers
-	oldunique := map[string]bool{}
-	for k, v := range d.Old.Headers {
-		// check if this header is excluded
-		if w, ok := excluded[k]; ok && w {
-		} else {
-			entry := k + ":" + strings.TrimSpace(v)
-			oldunique[entry] = true
+		old, new := trimValues(oldvals), trimValues(d.New.Headers[k])
+		if old != new && !d.Exclusions.excludedChange(HeaderValue, k, old, new) {
+			c.Modified = append(c.Modified, Entry{Key: k, Old: old, New: new})
 		}
 	}
-	found := ""
-
-	newunique := map[string]bool{}
-	for k, v := range d.New.Headers {
-		// check if this header is excluded
-		if w, ok := excluded[k]; ok && w {
-		} else {
-			entry := k + ":" + strings.TrimSpace(v)
-			newunique[entry] = true
-			// Check if any new request contains any additional value
-			if w, ok := oldunique[entry]; !ok && !w {
-				found += entry + " // Change In Header:Value\n"
-			}
+
+	if c.IsEmpty() {
+		return nil
+	}
+	return c
+}
+
+func (d *DualResponseComparer) compareCookies() *Change {
+	// If any changes in cookie is observed
+	// i.e New cookies are set in response
+	c := &Change{Type: Cookie}
+	for _, k := range sortedCookieKeys(d.New.Cookies) {
+		if _, ok := d.Old.Cookies[k]; !ok && !d.Exclusions.Excluded(Cookie, k, d.New.Cookies[k].Value) {
+			c.Added = append(c.Added, Entry{Key: k, New: d.New.Cookies[k].Value})
 		}
 	}
-	//Check if any headers are missing
-	for k, _ := range oldunique {
-		// Check if any new request contains any additional value
-		if w, ok := newunique[k]; !ok && !w {
-			found += k + " // Change In Header:Value\n"
+	//Check if any Cookies are missing
+	for _, k := range sortedCookieKeys(d.Old.Cookies) {
+		if _, ok := d.New.Cookies[k]; !ok && !d.Exclusions.Excluded(Cookie, k, d.Old.Cookies[k].Value) {
+			c.Removed = append(c.Removed, Entry{Key: k, Old: d.Old.Cookies[k].Value})
 		}
 	}
 
-	if found != "" {
-		// Changes found
-		hc := Change{
-			Type: HeaderValue,
-			Old:  "", // Doesnot make sense
-			New:  found,
+	if c.IsEmpty() {
+		return nil
+	}
+	return c
+}
+
+func (d *DualResponseComparer) compareCookieValues() *Change {
+	// Only cookies present in both responses are compared
+	// (ex: session cookie is changed after login)
+	c := &Change{Type: CookieValue}
+	for _, k := range sortedCookieKeys(d.New.Cookies) {
+		old, ok := d.Old.Cookies[k]
+		if !ok {
+			continue
+		}
+		new := d.New.Cookies[k].Value
+		if old.Value != new && !d.Exclusions.excludedChange(CookieValue, k, old.Value, new) {
+			c.Modified = append(c.Modified, Entry{Key: k, Old: old.Value, New: new})
 		}
-		return &hc
 	}
 
-	return nil
+	if c.IsEmpty() {
+		return nil
+	}
+	return c
 }
 
-func (d *DualResponseComparer) compareCookies() *Change {
-	// If any changes in cookie is observed
-	// i.e New cookies are set in response
-	excluded := map[string]bool{}
-	if w, ok := Exclusions[Cookie]; ok {
-		for _, v := range w {
-			excluded[v] = true
-		}
+func (d *DualResponseComparer) compareJSONKeys() *Change {
+	// Only applicable if both bodies are json
+	// Keys are compared using schema paths (ex: items.#.id)
+	// so that different length of arrays is not a change
+	// values of entries are types of keys
+	oldschema, err := d.Old.JSONSchema()
+	if err != nil {
+		return nil
 	}
-	// Change If any cookie was added /removed
-	// Unique map of old Cookies
-	oldunique := map[string]bool{}
-	for k, _ := range d.Old.Cookies {
-		// check if this cookie is excluded
-		if w, ok := excluded[k]; ok && w {
-		} else {
-			oldunique[k] = true
-		}
+	newschema, err := d.New.JSONSchema()
+	if err != nil {
+		return nil
 	}
-	found := ""
-
-	newunique := map[string]bool{}
-	for k, _ := range d.New.Cookies {
-		// check if this cookie is excluded
-		if w, ok := excluded[k]; ok && w {
-		} else {
-			newunique[k] = true
-			// Check if any new request contains any additional value
-			if w, ok := oldunique[k]; !ok && !w {
-				found += k + " // Extra Cookie\n"
-			}
+
+	c := &Change{Type: JSONKey}
+	for _, k := range newschema.Paths() {
+		if _, ok := oldschema.Field(k); !ok && !d.Exclusions.Excluded(JSONKey, k, "") {
+			f, _ := newschema.Field(k)
+			c.Added = append(c.Added, Entry{Key: k, New: jsonTypes(f)})
 		}
 	}
-	//Check if any Cookies are missing
-	for k, _ := range oldunique {
-		// Check if any new request contains any additional value
-		if w, ok := newunique[k]; !ok && !w {
-			found += k + " // Missing Cookie\n"
+	for _, k := range oldschema.Paths() {
+		if _, ok := newschema.Field(k); !ok && !d.Exclusions.Excluded(JSONKey, k, "") {
+			f, _ := oldschema.Field(k)
+			c.Removed = append(c.Removed, Entry{Key: k, Old: jsonTypes(f)})
 		}
 	}
 
-	if found != "" {
-		// Changes found
-		hc := Change{
-			Type: Cookie,
-			Old:  "", // Doesnot make sense
-			New:  found,
-		}
-		return &hc
+	if c.IsEmpty() {
+		return nil
+	}
+	return c
+}
+
+func sortedHeaderKeys(h http.Header) []string {
+	keys := []string{}
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedCookieKeys(c map[string]*http.Cookie) []string {
+	keys := []string{}
+	for k := range c {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// trimValues : values of repeated header joined in order
+func trimValues(values []string) string {
+	trimmed := make([]string, len(values))
+	for i, v := range values {
+		trimmed[i] = strings.TrimSpace(v)
+	}
+	return strings.Join(trimmed, ", ")
+}
+
+func jsonTypes(f rawhttp.JSONField) string {
+	types := []string{}
+	for _, t := range f.Types {
+		types = append(types, rawhttp.JSONTypeString(t))
+	}
+	return strings.Join(types, "|")
+}
+
+func (d *DualResponseComparer) compareBody() *Change {
+	threshold := d.BodyThreshold
+	if threshold <= 0 {
+		threshold = DefaultBodyThreshold
+	}
+
+	old, new := d.Exclusions.Remove(d.Old.Body), d.Exclusions.Remove(d.New.Body)
+
+	score := Similarity(old, new)
+	if score >= threshold {
+		return nil
+	}
+
+	change := &Change{
+		Type:  Body,
+		Old:   "", // Doesnot make sense
+		New:   strconv.FormatFloat(score, 'f', 4, 64) + " // Similarity",
+		Score: score,
+	}
+	if d.DiffOptions != nil {
+		change.Diff = diff.Unified(old, new, d.DiffOptions)
+	}
+	return change
+}
+
+// BodyDiff : Unified diff of old and new body (Body exclusions are removed and bodies are normalized)
+// Empty string is returned if bodies are same
+func (d *DualResponseComparer) BodyDiff(opts *diff.Options) string {
+	if d.Old == nil || d.New == nil {
+		return ""
+	}
+	old, new := d.Old.Body, d.New.Body
+	if d.Normalizer != nil {
+		old, new = d.Normalizer.Normalize(old), d.Normalizer.Normalize(new)
+	}
+	return diff.Unified(d.Exclusions.Remove(old), d.Exclusions.Remove(new), opts)
+}
+
+func (d *DualResponseComparer) compareTiming() *Change {
+	// Only applicable if time of both responses is known
+	if d.Old.Duration <= 0 || d.New.Duration <= 0 {
+		return nil
+	}
+	tolerance := d.Tolerance.Timing
+	if tolerance <= 0 {
+		tolerance = DefaultTimingTolerance
+	}
+	delta := d.New.Duration - d.Old.Duration
+	if delta < 0 {
+		delta = -delta
+	}
+	if delta <= tolerance {
+		return nil
+	}
+	return &Change{
+		Type: Timing,
+		Old:  d.Old.Duration.String(),
+		New:  d.New.Duration.String(),
+	}
+}
+
+// redirectHost : lowercase host of location (empty if location is relative)
+func redirectHost(location string) string {
+	if location == "" {
+		return ""
+	}
+	u, err := url.Parse(strings.TrimSpace(location))
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Host)
+}
+
+// bodyHash : sha256 of body
+func bodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// pageTitle : title of html page (empty if not html)
+func pageTitle(resp *rawhttp.RawHttpResponse) string {
+	if !strings.Contains(strings.ToLower(resp.ContentType), "html") {
+		return ""
+	}
+	doc, err := html.NewDocument(resp.Body, "")
+	if err != nil {
+		return ""
+	}
+	return doc.Title()
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
 	}
-	return nil
+	return x
 }
 
 func NewDualResponseComparer(old *rawhttp.RawHttpResponse, new *rawhttp.RawHttpResponse) *DualResponseComparer {
 	return &DualResponseComparer{
-		Old:    old,
-		New:    new,
-		Ignore: map[Factor]bool{HeaderValue: true},
+		Old:        old,
+		New:        new,
+		Ignore:     DefaultIgnore(),
+		Exclusions: DefaultExclusions(),
 	}
 }
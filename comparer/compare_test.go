@@ -2,10 +2,13 @@ package comparer_test
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/tarunKoyalwar/goseclibs/comparer"
 	"github.com/tarunKoyalwar/goseclibs/rawhttp"
@@ -150,3 +153,179 @@ func HandleError(t *testing.T, err error) {
 		t.Errorf("got an error while sending request %v", err)
 	}
 }
+
+func Test_CompareAcrossSources(t *testing.T) {
+	// live response
+	live, err := rawhttp.NewRawHttpResponse(&http.Response{
+		StatusCode: 200,
+		Header: http.Header{
+			"Date":            {"Mon, 01 Jan 2024 00:00:00 GMT"},
+			"Content-Type":    {"text/html"},
+			"X-Frame-Options": {"DENY"},
+		},
+		Body: io.NopCloser(strings.NewReader("hello")),
+	})
+	if err != nil {
+		t.Fatalf("failed to parse response %v", err)
+	}
+
+	// same response exported by burp with lowercase headers
+	burp, err := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\ndate: Tue, 02 Jan 2024 00:00:00 GMT\ncontent-type: text/html\nx-frame-options: DENY\n\nhello"))
+	if err != nil {
+		t.Fatalf("failed to parse response %v", err)
+	}
+
+	if burp.Headers.Get("X-FRAME-OPTIONS") != "DENY" {
+		t.Errorf("case-insensitive lookup failed %v", burp.Headers)
+	}
+
+	c := comparer.NewDualResponseComparer(live, burp)
+	c.Ignore = map[comparer.Factor]bool{}
+	c.Exclusions.Add(comparer.HeaderValue, "date")
+
+	changes, _ := c.Compare()
+	if len(changes) != 0 {
+		t.Errorf("expected no changes got %v", changes)
+	}
+}
+
+func Test_CompareJSONKeys(t *testing.T) {
+	parse := func(body string) *rawhttp.RawHttpResponse {
+		resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n" + body))
+		if err != nil {
+			t.Fatalf("failed to parse response %v", err)
+		}
+		return resp
+	}
+
+	user := parse(`{"id": 1, "orders": [{"id": 1}]}`)
+	admin := parse(`{"id": 2, "orders": [{"id": 1}, {"id": 2}], "permissions": ["all"]}`)
+
+	c := comparer.NewDualResponseComparer(user, admin)
+	c.Ignore = map[comparer.Factor]bool{comparer.HeaderValue: true, comparer.ContentLength: true, comparer.Body: true, comparer.BodyHash: true, comparer.Words: true, comparer.Lines: true}
+
+	changes, _ := c.Compare()
+	if len(changes) != 1 || changes[0].Type != comparer.JSONKey {
+		t.Fatalf("expected JSONKey change got %v", changes)
+	}
+	if len(changes[0].Added) != 2 || changes[0].Added[0].Key != "permissions" || changes[0].Added[1].Key != "permissions.#" || len(changes[0].Removed) != 0 {
+		t.Errorf("unexpected change %v", changes[0])
+	}
+
+	// ignored by default
+	changes, _ = comparer.NewDualResponseComparer(user, admin).Compare()
+	for _, v := range changes {
+		if v.Type == comparer.JSONKey {
+			t.Errorf("JSONKey should be ignored by default")
+		}
+	}
+}
+
+func Test_StructuredChanges(t *testing.T) {
+	old, _ := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nServer: nginx\r\nX-Cache: HIT\r\nSet-Cookie: session=abc\r\nSet-Cookie: theme=dark\r\n\r\nhello"))
+	new, _ := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nServer: apache\r\nX-Admin: true\r\nSet-Cookie: session=xyz\r\nSet-Cookie: role=admin\r\n\r\nhello"))
+
+	c := comparer.NewDualResponseComparer(old, new)
+	c.Ignore = map[comparer.Factor]bool{}
+
+	changes, _ := c.Compare()
+	got := map[comparer.Factor]comparer.Change{}
+	for _, v := range changes {
+		got[v.Type] = v
+	}
+	if len(changes) != 4 {
+		t.Fatalf("expected 4 changes got %v", changes)
+	}
+
+	h := got[comparer.Header]
+	if len(h.Added) != 1 || h.Added[0] != (comparer.Entry{Key: "X-Admin", New: "true"}) ||
+		len(h.Removed) != 1 || h.Removed[0] != (comparer.Entry{Key: "X-Cache", Old: "HIT"}) {
+		t.Errorf("unexpected header change %v", h)
+	}
+
+	hv := got[comparer.HeaderValue]
+	if len(hv.Modified) != 1 || hv.Modified[0] != (comparer.Entry{Key: "Server", Old: "nginx", New: "apache"}) {
+		t.Errorf("unexpected header value change %v", hv)
+	}
+
+	ck := got[comparer.Cookie]
+	if len(ck.Added) != 1 || ck.Added[0].Key != "role" || len(ck.Removed) != 1 || ck.Removed[0].Key != "theme" {
+		t.Errorf("unexpected cookie change %v", ck)
+	}
+
+	cv := got[comparer.CookieValue]
+	if len(cv.Modified) != 1 || cv.Modified[0] != (comparer.Entry{Key: "session", Old: "abc", New: "xyz"}) {
+		t.Errorf("unexpected cookie value change %v", cv)
+	}
+
+	expected := "Header:\n  + X-Admin: true\n  - X-Cache: HIT"
+	if h.String() != expected {
+		t.Errorf("expected %q got %q", expected, h.String())
+	}
+	sc := comparer.Change{Type: comparer.StatusCode, Old: "200", New: "302"}
+	if sc.String() != "StatusCode: 200 => 302" {
+		t.Errorf("unexpected string %q", sc.String())
+	}
+}
+
+func Test_ContentFactors(t *testing.T) {
+	parse := func(raw string) *rawhttp.RawHttpResponse {
+		resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte(raw))
+		if err != nil {
+			t.Fatalf("failed to parse response %v", err)
+		}
+		return resp
+	}
+
+	old := parse("HTTP/1.1 302 Found\r\nContent-Type: text/html\r\nLocation: https://example.com/login\r\n\r\n<html><head><title>Login</title></head>\n<body>please login</body></html>\n")
+	new := parse("HTTP/1.1 302 Found\r\nContent-Type: text/html\r\nLocation: https://EVIL.com/login\r\n\r\n<html><head><title>Dashboard</title></head>\n<body>welcome back admin\n</body></html>\n")
+	old.Duration = 100 * time.Millisecond
+	new.Duration = 3 * time.Second
+
+	c := comparer.NewDualResponseComparer(old, new)
+	c.Ignore = map[comparer.Factor]bool{comparer.Body: true, comparer.ContentLength: true, comparer.Location: true}
+
+	changes, _ := c.Compare()
+	got := map[comparer.Factor]comparer.Change{}
+	for _, v := range changes {
+		got[v.Type] = v
+	}
+
+	expected := map[comparer.Factor][2]string{
+		comparer.RedirectHost: {"example.com", "evil.com"},
+		comparer.Title:        {"Login", "Dashboard"},
+		comparer.Words:        {"3", "5"},
+		comparer.Lines:        {"2", "3"},
+		comparer.Timing:       {"100ms", "3s"},
+	}
+	for f, v := range expected {
+		if got[f].Old != v[0] || got[f].New != v[1] {
+			t.Errorf("expected %v change %v got %v", comparer.FactorString(f), v, got[f])
+		}
+	}
+	if _, ok := got[comparer.BodyHash]; !ok || len(changes) != 6 {
+		t.Errorf("unexpected changes %v", changes)
+	}
+
+	// tolerance
+	c.Tolerance = comparer.Tolerance{Words: 2, Lines: 1, Timing: 5 * time.Second}
+	changes, _ = c.Compare()
+	for _, v := range changes {
+		if v.Type == comparer.Words || v.Type == comparer.Lines || v.Type == comparer.Timing {
+			t.Errorf("change within tolerance reported %v", v)
+		}
+	}
+
+	// relative location has no host and unknown time is not compared
+	new.Location = "/dashboard"
+	new.Duration = 0
+	changes, _ = c.Compare()
+	for _, v := range changes {
+		if v.Type == comparer.RedirectHost && (v.Old != "example.com" || v.New != "") {
+			t.Errorf("unexpected redirect host change %v", v)
+		}
+		if v.Type == comparer.Timing {
+			t.Errorf("timing must not be compared if unknown")
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package comparer
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+/*
+Custom Factors
+
+Comparison logic not covered by builtin factors can be plugged in
+by implementing FactorComparer and adding it to Custom of comparers
+
+Each custom factor gets its own Factor value (based on its name) which
+1. is used as Type of changes it returns
+2. can be used in Ignore map to disable it
+3. is resolved to its name by FactorString
+
+Builtin custom factors
+1. ContainsFactor  => presence of text in body is changed (ex: Welcome admin)
+2. RegexFactor     => match of regex in body is changed
+3. JSONFieldFactor => value of json field is changed
+4. FactorFunc      => any function
+*/
+
+// FactorComparer : Custom comparison logic
+type FactorComparer interface {
+	Name() string
+	// Compare : Change if old and new are different (nil otherwise)
+	Compare(old *rawhttp.RawHttpResponse, new *rawhttp.RawHttpResponse) *Change
+}
+
+// customFactorBase : Factor values of custom factors start from here
+const customFactorBase Factor = 1000
+
+var customFactors = struct {
+	sync.RWMutex
+	names []string
+	ids   map[string]Factor
+}{ids: map[string]Factor{}}
+
+// FactorOf : Factor value of custom factor (same for all factors with same name)
+func FactorOf(f FactorComparer) Factor {
+	return RegisterFactor(f.Name())
+}
+
+// RegisterFactor : Factor value for custom factor name
+func RegisterFactor(name string) Factor {
+	customFactors.RLock()
+	id, ok := customFactors.ids[name]
+	customFactors.RUnlock()
+	if ok {
+		return id
+	}
+
+	customFactors.Lock()
+	defer customFactors.Unlock()
+	if id, ok := customFactors.ids[name]; ok {
+		return id
+	}
+	id = customFactorBase + Factor(len(customFactors.names))
+	customFactors.names = append(customFactors.names, name)
+	customFactors.ids[name] = id
+	return id
+}
+
+// customFactorName : name of custom factor (empty if not registered)
+func customFactorName(f Factor) string {
+	customFactors.RLock()
+	defer customFactors.RUnlock()
+	i := int(f - customFactorBase)
+	if i < 0 || i >= len(customFactors.names) {
+		return ""
+	}
+	return customFactors.names[i]
+}
+
+// compareCustom : run custom factors which are not ignored
+func (d *DualResponseComparer) compareCustom() []Change {
+	changes := []Change{}
+	for _, f := range d.Custom {
+		id := FactorOf(f)
+		if d.Ignore[id] {
+			continue
+		}
+		if c := f.Compare(d.Old, d.New); c != nil {
+			c.Type = id
+			changes = append(changes, *c)
+		}
+	}
+	return changes
+}
+
+// factorFunc : FactorComparer using function
+type factorFunc struct {
+	name string
+	fn   func(old *rawhttp.RawHttpResponse, new *rawhttp.RawHttpResponse) *Change
+}
+
+func (f *factorFunc) Name() string {
+	return f.name
+}
+
+func (f *factorFunc) Compare(old *rawhttp.RawHttpResponse, new *rawhttp.RawHttpResponse) *Change {
+	return f.fn(old, new)
+}
+
+// FactorFunc : Custom factor using function
+func FactorFunc(name string, fn func(old *rawhttp.RawHttpResponse, new *rawhttp.RawHttpResponse) *Change) FactorComparer {
+	return &factorFunc{name: name, fn: fn}
+}
+
+// ContainsFactor : Change if text is present in only one of the bodies (case-sensitive)
+func ContainsFactor(name string, text string) FactorComparer {
+	return FactorFunc(name, func(old *rawhttp.RawHttpResponse, new *rawhttp.RawHttpResponse) *Change {
+		a, b := bytes.Contains(old.Body, []byte(text)), bytes.Contains(new.Body, []byte(text))
+		if a == b {
+			return nil
+		}
+		return &Change{
+			Old: strconv.FormatBool(a),
+			New: strconv.FormatBool(b),
+		}
+	})
+}
+
+// RegexFactor : Change if matches of regex in bodies are different
+// If regex has capturing groups first group is compared
+func RegexFactor(name string, pattern string) (FactorComparer, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex for factor %v: %v", name, err)
+	}
+	matches := func(body []byte) string {
+		found := []string{}
+		for _, m := range re.FindAllSubmatch(body, -1) {
+			if len(m) > 1 {
+				found = append(found, string(m[1]))
+			} else {
+				found = append(found, string(m[0]))
+			}
+		}
+		return strings.Join(found, ", ")
+	}
+	return FactorFunc(name, func(old *rawhttp.RawHttpResponse, new *rawhttp.RawHttpResponse) *Change {
+		a, b := matches(old.Body), matches(new.Body)
+		if a == b {
+			return nil
+		}
+		return &Change{
+			Old: a,
+			New: b,
+		}
+	}), nil
+}
+
+// JSONFieldFactor : Change if value of json field is different (see rawhttp.QueryJSON for query syntax)
+func JSONFieldFactor(name string, query string) FactorComparer {
+	value := func(resp *rawhttp.RawHttpResponse) string {
+		res := resp.JSON(query)
+		if !res.Exists {
+			return ""
+		}
+		return res.Raw()
+	}
+	return FactorFunc(name, func(old *rawhttp.RawHttpResponse, new *rawhttp.RawHttpResponse) *Change {
+		a, b := value(old), value(new)
+		if a == b {
+			return nil
+		}
+		return &Change{
+			Old: a,
+			New: b,
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package comparer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/comparer"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+func Test_CustomFactors(t *testing.T) {
+	parse := func(body string) *rawhttp.RawHttpResponse {
+		resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n" + body))
+		if err != nil {
+			t.Fatalf("failed to parse response %v", err)
+		}
+		return resp
+	}
+
+	old := parse(`{"role": "guest", "message": "Welcome guest", "csrf": "token-aaaa"}`)
+	new := parse(`{"role": "admin", "message": "Welcome admin", "csrf": "token-bbbb"}`)
+
+	admin := comparer.ContainsFactor("AdminWelcome", "Welcome admin")
+	role := comparer.JSONFieldFactor("Role", "role")
+	token, err := comparer.RegexFactor("TokenPrefix", `"csrf": "(\w+)-`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := comparer.RegexFactor("Invalid", `(`); err == nil {
+		t.Errorf("expected error for invalid regex")
+	}
+	always := comparer.FactorFunc("Always", func(old, new *rawhttp.RawHttpResponse) *comparer.Change {
+		return &comparer.Change{New: "changed"}
+	})
+
+	c := comparer.NewDualResponseComparer(old, new)
+	c.Ignore[comparer.ContentLength] = true
+	c.Custom = []comparer.FactorComparer{admin, role, token, always}
+
+	changes, _ := c.Compare()
+	got := map[string]comparer.Change{}
+	for _, v := range changes {
+		got[comparer.FactorString(v.Type)] = v
+	}
+	if len(changes) != 3 {
+		t.Errorf("expected 3 changes got %v", changes)
+	}
+	if v := got["AdminWelcome"]; v.Old != "false" || v.New != "true" || v.Type != comparer.FactorOf(admin) {
+		t.Errorf("unexpected contains change %v", v)
+	}
+	if v := got["Role"]; v.Old != `"guest"` || v.New != `"admin"` {
+		t.Errorf("unexpected json field change %v", v)
+	}
+	if _, ok := got["TokenPrefix"]; ok {
+		t.Errorf("same regex match must not be reported")
+	}
+
+	// factors with same name have same value
+	if comparer.FactorOf(admin) != comparer.RegisterFactor("AdminWelcome") || comparer.FactorOf(admin) == comparer.FactorOf(role) {
+		t.Errorf("unexpected factor values")
+	}
+
+	// custom factors are disabled using ignore
+	c.Ignore[comparer.FactorOf(always)] = true
+	c.Ignore[comparer.FactorOf(role)] = true
+	if changes, _ := c.Compare(); len(changes) != 1 || changes[0].Type != comparer.FactorOf(admin) {
+		t.Errorf("expected only admin change got %v", changes)
+	}
+
+	// one2many comparer
+	m := comparer.NewOne2ManyResponseComparer(old, old, new)
+	m.Ignore[comparer.ContentLength] = true
+	m.Custom = []comparer.FactorComparer{admin}
+	if res := m.Compare(context.Background()); len(res) != 1 || res[0].Resp != new {
+		t.Errorf("expected change only for admin response got %v", res)
+	}
+}
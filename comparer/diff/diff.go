@@ -0,0 +1,393 @@
+package diff
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+Line & Word Level Diff Engine
+
+Uses Myers O(ND) algorithm with linear space refinement (middle snake)
+1. Common prefix & suffix are stripped before diffing
+2. Lines/Words are interned as integers (normalized if whitespace or case is ignored)
+3. If edit distance of a region exceeds MaxCost ,best partial path is used
+   (result may not be minimal but diff of large & completely different
+   bodies does not take quadratic time)
+
+Output Formats
+1. Unified diff (same as diff -u)
+2. Word diff (same as git diff --word-diff) => [-removed-]{+added+}
+
+Normalization only affects comparison . Output always contains original text
+*/
+
+// Op : Type of edit
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// OpString : Name of edit type
+func OpString(z Op) string {
+	switch z {
+	case Equal:
+		return "Equal"
+	case Insert:
+		return "Insert"
+	case Delete:
+		return "Delete"
+	default:
+		return "Invalid"
+	}
+}
+
+// Edit : Single line/word of diff
+type Edit struct {
+	Op       Op
+	Text     string
+	OldIndex int // Index of line/word in old (-1 for inserts)
+	NewIndex int // Index of line/word in new (-1 for deletes)
+}
+
+// Options : Diff Options
+type Options struct {
+	Context          int    // Lines of context around changes in unified diff (Default: 3)
+	IgnoreWhitespace bool   // Ignore changes in amount of whitespace (and leading/trailing whitespace of lines)
+	IgnoreCase       bool   // Case insensitive comparison
+	OldName          string // Name of old file in unified diff header (Default: old)
+	NewName          string // Name of new file in unified diff header (Default: new)
+	MaxCost          int    // Max edit distance explored per region (Default: 2000 , <0 => unlimited)
+}
+
+// DefaultOptions : Default Options
+func DefaultOptions() *Options {
+	return &Options{
+		Context: 3,
+		OldName: "old",
+		NewName: "new",
+		MaxCost: 2000,
+	}
+}
+
+// Lines : Line level diff
+func Lines(old []byte, new []byte, opts *Options) []Edit {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return diff(splitLines(string(old)), splitLines(string(new)), opts, normalizeLine)
+}
+
+// Words : Word level diff (words ,whitespace and symbols are separate tokens)
+func Words(old []byte, new []byte, opts *Options) []Edit {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return diff(splitWords(string(old)), splitWords(string(new)), opts, normalizeWord)
+}
+
+// Stats : Number of inserted and deleted lines/words
+func Stats(edits []Edit) (int, int) {
+	inserted, deleted := 0, 0
+	for _, e := range edits {
+		switch e.Op {
+		case Insert:
+			inserted++
+		case Delete:
+			deleted++
+		}
+	}
+	return inserted, deleted
+}
+
+// IsEqual : Check if there are no changes
+func IsEqual(edits []Edit) bool {
+	for _, e := range edits {
+		if e.Op != Equal {
+			return false
+		}
+	}
+	return true
+}
+
+func diff(a []string, b []string, opts *Options, normalize func(string, *Options) string) []Edit {
+	// intern tokens
+	ids := map[string]int{}
+	intern := func(tokens []string) []int {
+		res := make([]int, len(tokens))
+		for i, t := range tokens {
+			key := normalize(t, opts)
+			id, ok := ids[key]
+			if !ok {
+				id = len(ids)
+				ids[key] = id
+			}
+			res[i] = id
+		}
+		return res
+	}
+
+	m := &myers{
+		a:       intern(a),
+		b:       intern(b),
+		maxcost: opts.MaxCost,
+	}
+	m.deleted = make([]bool, len(a))
+	m.inserted = make([]bool, len(b))
+	if m.maxcost == 0 {
+		m.maxcost = DefaultOptions().MaxCost
+	}
+	m.compare(0, len(a), 0, len(b))
+
+	// build edit script (deletions before insertions)
+	edits := []Edit{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && m.deleted[i]:
+			edits = append(edits, Edit{Op: Delete, Text: a[i], OldIndex: i, NewIndex: -1})
+			i++
+		case j < len(b) && m.inserted[j]:
+			edits = append(edits, Edit{Op: Insert, Text: b[j], OldIndex: -1, NewIndex: j})
+			j++
+		default:
+			edits = append(edits, Edit{Op: Equal, Text: b[j], OldIndex: i, NewIndex: j})
+			i++
+			j++
+		}
+	}
+
+	return edits
+}
+
+// splitLines : split text into lines (newline is not part of line)
+func splitLines(s string) []string {
+	if s == "" {
+		return []string{}
+	}
+	lines := strings.Split(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i := range lines {
+		lines[i] = strings.TrimSuffix(lines[i], "\r")
+	}
+	return lines
+}
+
+// splitWords : split text into words ,whitespace runs and symbols
+func splitWords(s string) []string {
+	tokens := []string{}
+	start := 0
+	kind := -1 // 0 => word ,1 => space
+
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		k := 2
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' {
+			k = 0
+		} else if unicode.IsSpace(r) {
+			k = 1
+		}
+
+		if k != kind || k == 2 {
+			if i > start {
+				tokens = append(tokens, s[start:i])
+			}
+			start = i
+			kind = k
+		}
+		i += size
+	}
+	if start < len(s) {
+		tokens = append(tokens, s[start:])
+	}
+
+	return tokens
+}
+
+func normalizeLine(s string, opts *Options) string {
+	if opts.IgnoreWhitespace {
+		s = strings.Join(strings.Fields(s), " ")
+	}
+	if opts.IgnoreCase {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+func normalizeWord(s string, opts *Options) string {
+	if opts.IgnoreWhitespace && strings.TrimSpace(s) == "" {
+		return " "
+	}
+	if opts.IgnoreCase {
+		s = strings.ToLower(s)
+	}
+	return s
+}
+
+/* Myers Algorithm (linear space) */
+
+type myers struct {
+	a, b     []int
+	deleted  []bool
+	inserted []bool
+	maxcost  int
+	vf, vb   []int
+}
+
+// compare : mark deleted and inserted tokens of a[xoff:xlim] and b[yoff:ylim]
+func (m *myers) compare(xoff, xlim, yoff, ylim int) {
+	for {
+		// common prefix & suffix
+		for xoff < xlim && yoff < ylim && m.a[xoff] == m.b[yoff] {
+			xoff++
+			yoff++
+		}
+		for xoff < xlim && yoff < ylim && m.a[xlim-1] == m.b[ylim-1] {
+			xlim--
+			ylim--
+		}
+
+		switch {
+		case xoff == xlim:
+			for j := yoff; j < ylim; j++ {
+				m.inserted[j] = true
+			}
+			return
+		case yoff == ylim:
+			for i := xoff; i < xlim; i++ {
+				m.deleted[i] = true
+			}
+			return
+		}
+
+		x, y, ok := m.split(xoff, xlim, yoff, ylim)
+		if !ok {
+			// no usable split point . Replace whole region
+			for i := xoff; i < xlim; i++ {
+				m.deleted[i] = true
+			}
+			for j := yoff; j < ylim; j++ {
+				m.inserted[j] = true
+			}
+			return
+		}
+
+		// recurse on smaller half and loop on the other
+		if (x-xoff)+(y-yoff) < (xlim-x)+(ylim-y) {
+			m.compare(xoff, x, yoff, y)
+			xoff, yoff = x, y
+		} else {
+			m.compare(x, xlim, y, ylim)
+			xlim, ylim = x, y
+		}
+	}
+}
+
+// split : find point on (near) optimal path between (xoff,yoff) and (xlim,ylim)
+// using middle snake . Returned point is strictly inside the region
+func (m *myers) split(xoff, xlim, yoff, ylim int) (int, int, bool) {
+	n, mm := xlim-xoff, ylim-yoff
+	delta := n - mm
+	odd := delta&1 != 0
+	max := (n + mm + 1) / 2
+	off := max + 1
+
+	size := 2*max + 3
+	if cap(m.vf) < size {
+		m.vf = make([]int, size)
+		m.vb = make([]int, size)
+	}
+	vf, vb := m.vf[:size], m.vb[:size]
+	vf[off+1] = 0
+	vb[off+1] = 0
+
+	valid := func(x, y int) bool {
+		if x < 0 || y < 0 || x > n || y > mm {
+			return false
+		}
+		return !(x == 0 && y == 0) && !(x == n && y == mm)
+	}
+
+	for d := 0; d <= max; d++ {
+		if m.maxcost > 0 && d > m.maxcost {
+			// too expensive . Use forward point which is furthest along
+			bestx, besty := -1, -1
+			for k := -d + 1; k <= d-1; k += 2 {
+				x := vf[off+k]
+				y := x - k
+				if valid(x, y) && x+y > bestx+besty {
+					bestx, besty = x, y
+				}
+			}
+			if bestx < 0 {
+				return 0, 0, false
+			}
+			return xoff + bestx, yoff + besty, true
+		}
+
+		// forward
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[off+k-1] < vf[off+k+1]) {
+				x = vf[off+k+1]
+			} else {
+				x = vf[off+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < mm && m.a[xoff+x] == m.b[yoff+y] {
+				x++
+				y++
+			}
+			vf[off+k] = x
+
+			kr := delta - k
+			if odd && kr >= -(d-1) && kr <= d-1 && x+vb[off+kr] >= n {
+				if valid(x0, y0) {
+					return xoff + x0, yoff + y0, true
+				}
+				if valid(x, y) {
+					return xoff + x, yoff + y, true
+				}
+				return 0, 0, false
+			}
+		}
+
+		// backward (on reversed sequences)
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && vb[off+k-1] < vb[off+k+1]) {
+				x = vb[off+k+1]
+			} else {
+				x = vb[off+k-1] + 1
+			}
+			y := x - k
+			x0, y0 := x, y
+			for x < n && y < mm && m.a[xlim-1-x] == m.b[ylim-1-y] {
+				x++
+				y++
+			}
+			vb[off+k] = x
+
+			kf := delta - k
+			if !odd && kf >= -d && kf <= d && x+vf[off+kf] >= n {
+				// convert to forward coordinates
+				if valid(n-x0, mm-y0) {
+					return xoff + n - x0, yoff + mm - y0, true
+				}
+				if valid(n-x, mm-y) {
+					return xoff + n - x, yoff + mm - y, true
+				}
+				return 0, 0, false
+			}
+		}
+	}
+
+	return 0, 0, false
+}
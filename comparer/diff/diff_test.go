@@ -0,0 +1,155 @@
+package diff_test
+
+import (
+	"math/rand"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/comparer/diff"
+)
+
+// lcs : length of longest common subsequence (dynamic programming)
+func lcs(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			if a[i-1] == b[j-1] {
+				dp[i][j] = dp[i-1][j-1] + 1
+			} else if dp[i-1][j] > dp[i][j-1] {
+				dp[i][j] = dp[i-1][j]
+			} else {
+				dp[i][j] = dp[i][j-1]
+			}
+		}
+	}
+	return dp[len(a)][len(b)]
+}
+
+func randomLines(r *rand.Rand, n int) []string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = string(rune('a' + r.Intn(4)))
+	}
+	return lines
+}
+
+func Test_Lines_Minimal(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 500; i++ {
+		a := randomLines(r, r.Intn(30))
+		b := randomLines(r, r.Intn(30))
+
+		edits := diff.Lines([]byte(strings.Join(a, "\n")), []byte(strings.Join(b, "\n")), nil)
+
+		// edits must reconstruct both sides
+		olds, news := []string{}, []string{}
+		equal := 0
+		for _, e := range edits {
+			if e.Op != diff.Insert {
+				olds = append(olds, e.Text)
+			}
+			if e.Op != diff.Delete {
+				news = append(news, e.Text)
+			}
+			if e.Op == diff.Equal {
+				equal++
+			}
+		}
+		if strings.Join(olds, ",") != strings.Join(a, ",") || strings.Join(news, ",") != strings.Join(b, ",") {
+			t.Fatalf("edits do not reconstruct input %v %v => %v", a, b, edits)
+		}
+		if equal != lcs(a, b) {
+			t.Fatalf("diff is not minimal %v %v: got %v common lines expected %v", a, b, equal, lcs(a, b))
+		}
+	}
+}
+
+func Test_Unified(t *testing.T) {
+	old := "line1\nline2\nline3\nline4\nline5\nline6\nline7\nline8\nline9\nline10\nline11\nline12\n"
+	new := "line1\nline2 changed\nline3\nline4\nline5\nline6\nline7\nline8\nline9\nline10\nline11\nline12\nline13\n"
+
+	expected := `--- old
++++ new
+@@ -1,5 +1,5 @@
+ line1
+-line2
++line2 changed
+ line3
+ line4
+ line5
+@@ -10,3 +10,4 @@
+ line10
+ line11
+ line12
++line13
+`
+	got := diff.Unified([]byte(old), []byte(new), nil)
+	if got != expected {
+		t.Errorf("unexpected diff\n%v", got)
+	}
+
+	// compare with diff -u (if available)
+	if _, err := exec.LookPath("diff"); err == nil {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "old"), []byte(old), 0644)
+		os.WriteFile(filepath.Join(dir, "new"), []byte(new), 0644)
+		cmd := exec.Command("diff", "-u", "--label", "old", "--label", "new", "old", "new")
+		cmd.Dir = dir
+		out, _ := cmd.Output()
+		if len(out) > 0 && string(out) != got {
+			t.Errorf("output differs from diff -u\n%v", string(out))
+		}
+	}
+
+	if diff.Unified([]byte(old), []byte(old), nil) != "" {
+		t.Errorf("diff of equal bodies must be empty")
+	}
+}
+
+func Test_Normalization(t *testing.T) {
+	old := []byte("<div>\n  Hello   World\n</div>\n")
+	new := []byte("<DIV>\n\tHello World  \n</DIV>\n")
+
+	if edits := diff.Lines(old, new, &diff.Options{IgnoreWhitespace: true, IgnoreCase: true}); !diff.IsEqual(edits) {
+		t.Errorf("expected no changes with normalization")
+	}
+	if edits := diff.Lines(old, new, &diff.Options{IgnoreWhitespace: true}); diff.IsEqual(edits) {
+		t.Errorf("expected changes in case")
+	}
+
+	// output contains original text
+	out := diff.Unified(old, new, &diff.Options{IgnoreWhitespace: true, Context: 0})
+	if !strings.Contains(out, "-<div>") || !strings.Contains(out, "+<DIV>") || strings.Contains(out, "Hello") {
+		t.Errorf("unexpected diff\n%v", out)
+	}
+}
+
+func Test_WordDiff(t *testing.T) {
+	got := diff.WordDiff([]byte("Welcome guest, you have 0 items"), []byte("Welcome admin, you have 12 items"), nil)
+	expected := "Welcome [-guest-]{+admin+}, you have [-0-]{+12+} items"
+	if got != expected {
+		t.Errorf("expected %v got %v", expected, got)
+	}
+}
+
+func Test_Large(t *testing.T) {
+	// completely different bodies must not take quadratic time
+	r := rand.New(rand.NewSource(2))
+	var a, b strings.Builder
+	for i := 0; i < 50000; i++ {
+		a.WriteString(string(rune('a'+r.Intn(26))) + string(rune('a'+r.Intn(26))) + "\n")
+		b.WriteString(string(rune('A'+r.Intn(26))) + string(rune('a'+r.Intn(26))) + "\n")
+	}
+	edits := diff.Lines([]byte(a.String()), []byte(b.String()), nil)
+	ins, del := diff.Stats(edits)
+	if ins != 50000 || del != 50000 {
+		t.Errorf("unexpected stats %v %v", ins, del)
+	}
+}
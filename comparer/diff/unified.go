@@ -0,0 +1,160 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unified : Unified diff of old and new (empty if there are no changes)
+func Unified(old []byte, new []byte, opts *Options) string {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	return FormatUnified(Lines(old, new, opts), opts)
+}
+
+// FormatUnified : Format line edits as unified diff
+func FormatUnified(edits []Edit, opts *Options) string {
+	if opts == nil {
+		opts = DefaultOptions()
+	}
+	if IsEqual(edits) {
+		return ""
+	}
+
+	context := opts.Context
+	if context < 0 {
+		context = 0
+	}
+	oldname, newname := opts.OldName, opts.NewName
+	if oldname == "" {
+		oldname = "old"
+	}
+	if newname == "" {
+		newname = "new"
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %v\n+++ %v\n", oldname, newname)
+
+	for i := 0; i < len(edits); {
+		// find next change
+		for i < len(edits) && edits[i].Op == Equal {
+			i++
+		}
+		if i >= len(edits) {
+			break
+		}
+
+		// hunk starts with context before change
+		start := i - context
+		if start < 0 {
+			start = 0
+		}
+
+		// extend hunk while changes are within 2*context lines
+		end := i
+		for end < len(edits) {
+			if edits[end].Op != Equal {
+				end++
+				continue
+			}
+			next := end
+			for next < len(edits) && edits[next].Op == Equal {
+				next++
+			}
+			if next >= len(edits) || next-end > 2*context {
+				end += context
+				if end > next {
+					end = next
+				}
+				break
+			}
+			end = next
+		}
+
+		oldbefore, newbefore := 0, 0
+		for _, e := range edits[:start] {
+			if e.OldIndex >= 0 {
+				oldbefore++
+			}
+			if e.NewIndex >= 0 {
+				newbefore++
+			}
+		}
+		writeHunk(&sb, edits[start:end], oldbefore, newbefore)
+		i = end
+	}
+
+	return sb.String()
+}
+
+// writeHunk : oldbefore & newbefore are number of lines before hunk
+func writeHunk(sb *strings.Builder, hunk []Edit, oldbefore int, newbefore int) {
+	oldcount, newcount := 0, 0
+	for _, e := range hunk {
+		if e.OldIndex >= 0 {
+			oldcount++
+		}
+		if e.NewIndex >= 0 {
+			newcount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%v +%v @@\n", hunkRange(oldbefore, oldcount), hunkRange(newbefore, newcount))
+
+	for _, e := range hunk {
+		switch e.Op {
+		case Equal:
+			sb.WriteString(" ")
+		case Insert:
+			sb.WriteString("+")
+		case Delete:
+			sb.WriteString("-")
+		}
+		sb.WriteString(e.Text)
+		sb.WriteString("\n")
+	}
+}
+
+// hunkRange : start,count (1 based) . Count is omitted if it is 1
+// empty range starts at line before hunk (same as diff -u)
+func hunkRange(before int, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%v,0", before)
+	case 1:
+		return fmt.Sprintf("%v", before+1)
+	default:
+		return fmt.Sprintf("%v,%v", before+1, count)
+	}
+}
+
+// WordDiff : Word level diff in git --word-diff format ([-removed-]{+added+})
+func WordDiff(old []byte, new []byte, opts *Options) string {
+	return FormatWords(Words(old, new, opts))
+}
+
+// FormatWords : Format word edits ([-removed-]{+added+})
+func FormatWords(edits []Edit) string {
+	var sb strings.Builder
+	for i := 0; i < len(edits); {
+		op := edits[i].Op
+		j := i
+		var group strings.Builder
+		for j < len(edits) && edits[j].Op == op {
+			group.WriteString(edits[j].Text)
+			j++
+		}
+		switch op {
+		case Equal:
+			sb.WriteString(group.String())
+		case Delete:
+			sb.WriteString("[-" + group.String() + "-]")
+		case Insert:
+			sb.WriteString("{+" + group.String() + "+}")
+		}
+		i = j
+	}
+	return sb.String()
+}
@@ -0,0 +1,228 @@
+package comparer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+/*
+Exclusions
+
+Keys of a factor which are not compared (ex: date header ,csrf cookie)
+Each comparer has its own exclusions
+
+Keys can be matched using
+1. Exact => x-request-id
+2. Glob  => x-amz-* (* matches any characters ,? matches single character)
+3. Regex => ^x-(amz|goog)-
+
+All matching is case-insensitive
+A nil *Exclusions excludes nothing and returns error when rules are added
+(comparers created without constructor have nil Exclusions)
+Rules with Value are value-based . Key is only excluded if its value matches
+regex (for modified values both old and new value must match)
+
+Keys are header names (Header ,HeaderValue) ,cookie names (Cookie ,CookieValue)
+and json paths (JSONKey) . Body exclusions are removed from both bodies
+before comparison (ex: csrf tokens ,reflected payloads)
+StatusCode ,ContentLength ,ContentType and Location do not have keys
+and are ignored using Ignore instead
+*/
+
+// MatchType : How key of exclusion is matched
+type MatchType int
+
+const (
+	ExactMatch MatchType = iota
+	GlobMatch
+	RegexMatch
+)
+
+// MatchTypeString : Name of match type
+func MatchTypeString(z MatchType) string {
+	switch z {
+	case ExactMatch:
+		return "Exact"
+	case GlobMatch:
+		return "Glob"
+	case RegexMatch:
+		return "Regex"
+	default:
+		return "Invalid"
+	}
+}
+
+// Exclusion : Excluded key of a factor
+type Exclusion struct {
+	Factor Factor
+	Match  MatchType
+	Key    string // Key pattern (or text removed from body)
+	Value  string // Optional value regex (empty => any value)
+
+	key   *regexp.Regexp
+	value *regexp.Regexp
+}
+
+// compile : compile key & value patterns
+func (x *Exclusion) compile() error {
+	var pattern string
+	switch x.Match {
+	case ExactMatch:
+		pattern = regexp.QuoteMeta(x.Key)
+	case GlobMatch:
+		pattern = globToRegex(x.Key)
+	case RegexMatch:
+		pattern = x.Key
+	default:
+		return fmt.Errorf("invalid match type %v", x.Match)
+	}
+	if x.Factor != Body {
+		// keys are matched completely
+		pattern = "^(?:" + pattern + ")$"
+	}
+
+	var err error
+	if x.key, err = regexp.Compile("(?i)" + pattern); err != nil {
+		return fmt.Errorf("invalid exclusion %v: %v", x.Key, err)
+	}
+	if x.Value != "" {
+		if x.value, err = regexp.Compile("(?i)" + x.Value); err != nil {
+			return fmt.Errorf("invalid exclusion value %v: %v", x.Value, err)
+		}
+	}
+	return nil
+}
+
+// Exclusions : Excluded keys of each factor
+type Exclusions struct {
+	rules map[Factor][]*Exclusion
+}
+
+// Add : Exclude keys (exact ,case-insensitive)
+func (e *Exclusions) Add(f Factor, keys ...string) error {
+	for _, k := range keys {
+		if err := e.AddRule(Exclusion{Factor: f, Match: ExactMatch, Key: k}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddGlob : Exclude keys matching glob patterns (ex: x-amz-*)
+func (e *Exclusions) AddGlob(f Factor, patterns ...string) error {
+	for _, p := range patterns {
+		if err := e.AddRule(Exclusion{Factor: f, Match: GlobMatch, Key: p}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddRegex : Exclude keys matching regex patterns
+func (e *Exclusions) AddRegex(f Factor, patterns ...string) error {
+	for _, p := range patterns {
+		if err := e.AddRule(Exclusion{Factor: f, Match: RegexMatch, Key: p}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AddRule : Add exclusion (use this for value-based exclusions)
+// Returns error if exclusions is nil (ex: comparer created without constructor)
+func (e *Exclusions) AddRule(x Exclusion) error {
+	if e == nil {
+		return fmt.Errorf("exclusions is nil (use NewExclusions)")
+	}
+	if err := x.compile(); err != nil {
+		return err
+	}
+	if e.rules == nil {
+		e.rules = map[Factor][]*Exclusion{}
+	}
+	e.rules[x.Factor] = append(e.rules[x.Factor], &x)
+	return nil
+}
+
+// Rules : Exclusions of factor
+func (e *Exclusions) Rules(f Factor) []Exclusion {
+	rules := []Exclusion{}
+	if e == nil {
+		return rules
+	}
+	for _, x := range e.rules[f] {
+		rules = append(rules, *x)
+	}
+	return rules
+}
+
+// Excluded : Check if key with given value is excluded
+func (e *Exclusions) Excluded(f Factor, key string, value string) bool {
+	if e == nil {
+		return false
+	}
+	for _, x := range e.rules[f] {
+		if x.key.MatchString(key) && (x.value == nil || x.value.MatchString(value)) {
+			return true
+		}
+	}
+	return false
+}
+
+// excludedChange : modified key is excluded if both values are excluded
+func (e *Exclusions) excludedChange(f Factor, key string, old string, new string) bool {
+	return e.Excluded(f, key, old) && e.Excluded(f, key, new)
+}
+
+// Remove : Remove body exclusions from data
+func (e *Exclusions) Remove(data []byte) []byte {
+	if e == nil {
+		return data
+	}
+	for _, x := range e.rules[Body] {
+		data = x.key.ReplaceAll(data, nil)
+	}
+	return data
+}
+
+// Clone : Copy of exclusions
+func (e *Exclusions) Clone() *Exclusions {
+	clone := &Exclusions{rules: map[Factor][]*Exclusion{}}
+	if e == nil {
+		return clone
+	}
+	for f, rules := range e.rules {
+		clone.rules[f] = append([]*Exclusion{}, rules...)
+	}
+	return clone
+}
+
+// globToRegex : * => any characters ,? => single character
+// body globs do not span multiple lines
+func globToRegex(glob string) string {
+	var sb strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			sb.WriteString(`[^\n]*?`)
+		case '?':
+			sb.WriteString(`[^\n]`)
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	return sb.String()
+}
+
+// NewExclusions : Empty exclusions
+func NewExclusions() *Exclusions {
+	return &Exclusions{rules: map[Factor][]*Exclusion{}}
+}
+
+// DefaultExclusions : Exclusions used by comparers by default (date header)
+func DefaultExclusions() *Exclusions {
+	e := NewExclusions()
+	e.Add(Header, "date")
+	return e
+}
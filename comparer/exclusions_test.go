@@ -0,0 +1,85 @@
+package comparer_test
+
+import (
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/comparer"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+func Test_Exclusions(t *testing.T) {
+	e := comparer.NewExclusions()
+	e.Add(comparer.Header, "X-Request-ID")
+	if err := e.AddGlob(comparer.Header, "x-amz-*"); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddRegex(comparer.Cookie, `^(ga|_ga_\w+)$`); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddRule(comparer.Exclusion{Factor: comparer.HeaderValue, Key: "x-cache", Value: `^(hit|miss)$`}); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.AddRegex(comparer.Header, `(`); err == nil {
+		t.Errorf("expected error for invalid regex")
+	}
+
+	cases := []struct {
+		factor   comparer.Factor
+		key      string
+		value    string
+		excluded bool
+	}{
+		{comparer.Header, "x-request-id", "", true},
+		{comparer.Header, "X-Request-Id-2", "", false},
+		{comparer.Header, "X-Amz-Cf-Id", "", true},
+		{comparer.Header, "X-Amzn-Trace", "", false},
+		{comparer.Cookie, "_ga_ABC123", "", true},
+		{comparer.Cookie, "session", "", false},
+		{comparer.HeaderValue, "X-Cache", "HIT", true},
+		{comparer.HeaderValue, "X-Cache", "Error from cloudfront", false},
+		{comparer.HeaderValue, "X-Request-Id", "", false},
+	}
+	for _, c := range cases {
+		if got := e.Excluded(c.factor, c.key, c.value); got != c.excluded {
+			t.Errorf("%v %v=%v expected %v got %v", comparer.FactorString(c.factor), c.key, c.value, c.excluded, got)
+		}
+	}
+	// comparers created without constructor have nil exclusions
+	d := &comparer.DualResponseComparer{}
+	if err := d.Exclusions.Add(comparer.Header, "date"); err == nil {
+		t.Errorf("expected error while adding to nil exclusions")
+	}
+	if err := d.Exclusions.AddGlob(comparer.Header, "x-*"); err == nil {
+		t.Errorf("expected error while adding to nil exclusions")
+	}
+	if d.Exclusions.Excluded(comparer.Header, "date", "") || len(d.Exclusions.Rules(comparer.Header)) != 0 {
+		t.Errorf("nil exclusions must not exclude anything")
+	}
+}
+
+func Test_CompareExclusions(t *testing.T) {
+	old, _ := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nDate: Mon, 02 Jan 2023 15:04:05 GMT\r\nX-Cache: MISS\r\n\r\n<p>token: aaaa</p>"))
+	new, _ := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nX-Amz-Cf-Id: 123\r\nX-Cache: HIT\r\n\r\n<p>token: bbbb</p>"))
+
+	c := comparer.NewDualResponseComparer(old, new)
+	c.Ignore = map[comparer.Factor]bool{comparer.BodyHash: true}
+	c.BodyThreshold = 1
+
+	// missing date header is excluded by default
+	if changes, _ := c.Compare(); len(changes) != 3 {
+		t.Fatalf("expected header ,header value and body changes got %v", changes)
+	}
+
+	c.Exclusions.AddGlob(comparer.Header, "x-amz-*")
+	c.Exclusions.AddRule(comparer.Exclusion{Factor: comparer.HeaderValue, Key: "x-cache", Value: "hit|miss"})
+	c.Exclusions.AddGlob(comparer.Body, "token: *<")
+	if changes, _ := c.Compare(); len(changes) != 0 {
+		t.Errorf("expected no changes got %v", changes)
+	}
+
+	// exclusions are not shared between comparers
+	other := comparer.NewDualResponseComparer(old, new)
+	if other.Exclusions.Excluded(comparer.Header, "X-Amz-Cf-Id", "") || !other.Exclusions.Excluded(comparer.Header, "Date", "") {
+		t.Errorf("unexpected default exclusions")
+	}
+}
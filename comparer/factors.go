@@ -1,5 +1,7 @@
 package comparer
 
+import "strings"
+
 /*
 Factors are nothing but places where changes are observed
 Whenever a Factor is Found its details are stored in
@@ -17,13 +19,92 @@ const (
 	Header               // Extra/Missing Header
 	HeaderValue          // Header Value is changed
 	Cookie               // Extra/Missing Cookie
+	JSONKey              // Extra/Missing Key in JSON Body (array indices are ignored)
+	Body                 // Body Content is changed (similarity below threshold)
+	CookieValue          // Cookie Value is changed
+	Words                // Change in number of words in body (beyond tolerance)
+	Lines                // Change in number of lines in body (beyond tolerance)
+	Title                // Change in HTML title
+	Timing               // Change in response time (beyond tolerance)
+	BodyHash             // Body is not identical (sha256)
+	RedirectHost         // Change in host of Location header
 )
 
+// DefaultIgnore : Factors ignored by default
+// (Factors which are expensive or noisy in most cases)
+func DefaultIgnore() map[Factor]bool {
+	return map[Factor]bool{
+		HeaderValue:  true,
+		CookieValue:  true,
+		JSONKey:      true,
+		Body:         true,
+		Words:        true,
+		Lines:        true,
+		Title:        true,
+		Timing:       true,
+		BodyHash:     true,
+		RedirectHost: true,
+	}
+}
+
+// Entry : Change of a single key (header ,cookie or json key)
+type Entry struct {
+	Key string
+	Old string // Value in old response (empty if added)
+	New string // Value in new response (empty if removed)
+}
+
 // Change : Change Observed For that particular Factor
+// Header ,HeaderValue ,Cookie ,CookieValue & JSONKey changes
+// have per key entries instead of Old & New
 type Change struct {
 	Type Factor // Type of Factor
 	Old  string // Old Value of this Factor
 	New  string // New Value of this Factor
+	// Similarity between old and new (0-1) . Only set for Body
+	Score float64
+	// Unified diff of bodies . Only set for Body if DiffOptions is given
+	Diff string
+
+	Added    []Entry // Keys only present in new response
+	Removed  []Entry // Keys only present in old response
+	Modified []Entry // Keys with different values
+}
+
+// IsEmpty : Check if change has no values and entries
+func (c *Change) IsEmpty() bool {
+	return c.Old == "" && c.New == "" && len(c.Added) == 0 && len(c.Removed) == 0 && len(c.Modified) == 0
+}
+
+// String : Human readable change
+func (c Change) String() string {
+	var sb strings.Builder
+	sb.WriteString(FactorString(c.Type) + ":")
+	switch {
+	case c.Old != "" && c.New != "":
+		sb.WriteString(" " + c.Old + " => " + c.New)
+	case c.New != "":
+		sb.WriteString(" " + c.New)
+	case c.Old != "":
+		sb.WriteString(" " + c.Old + " => (none)")
+	}
+	for _, e := range c.Added {
+		sb.WriteString("\n  + " + entryString(e.Key, e.New))
+	}
+	for _, e := range c.Removed {
+		sb.WriteString("\n  - " + entryString(e.Key, e.Old))
+	}
+	for _, e := range c.Modified {
+		sb.WriteString("\n  ~ " + e.Key + ": " + e.Old + " => " + e.New)
+	}
+	return sb.String()
+}
+
+func entryString(key string, value string) string {
+	if value == "" {
+		return key
+	}
+	return key + ": " + value
 }
 
 func FactorString(z Factor) string {
@@ -42,7 +123,28 @@ func FactorString(z Factor) string {
 		return "HeaderValue"
 	case Cookie:
 		return "Cookie"
+	case JSONKey:
+		return "JSONKey"
+	case Body:
+		return "Body"
+	case CookieValue:
+		return "CookieValue"
+	case Words:
+		return "Words"
+	case Lines:
+		return "Lines"
+	case Title:
+		return "Title"
+	case Timing:
+		return "Timing"
+	case BodyHash:
+		return "BodyHash"
+	case RedirectHost:
+		return "RedirectHost"
 	default:
+		if name := customFactorName(z); name != "" {
+			return name
+		}
 		return "Invalid"
 	}
 }
@@ -3,8 +3,10 @@ package comparer
 import (
 	"context"
 	"runtime"
+	"sort"
 	"sync"
 
+	"github.com/tarunKoyalwar/goseclibs/comparer/diff"
 	"github.com/tarunKoyalwar/goseclibs/rawhttp"
 )
 
@@ -16,6 +18,9 @@ but can improved in headers,headervalue,cookies comparison
 Since it is a wrap around DualResponseComparer . Comparison can be
 accelerated using goroutines
 
+Responses can be given upfront (Many) or streamed using a channel (Stream)
+Every result is tagged with index of response (and ID of streamed item)
+
 */
 
 // One2ManyResposeComparer : Ideal For testing many vulnerabilites
@@ -25,105 +30,201 @@ type One2ManyResponseComparer struct {
 	Original *rawhttp.RawHttpResponse
 	Many     []*rawhttp.RawHttpResponse
 	Ignore   map[Factor]bool /* These Factors are Ignored and are not calculated
-	By default all Factors are considered except HeaderValue*/
-	Concurrency int
+	By default all Factors are considered except DefaultIgnore() (HeaderValue ,CookieValue ,JSONKey ,Body and content metrics)*/
+	Concurrency   int
+	BodyThreshold float64          // Minimum similarity of bodies (Default: DefaultBodyThreshold)
+	DiffOptions   *diff.Options    // If not nil unified diff of bodies is added to Body change
+	Normalizer    *Normalizer      // If not nil dynamic content is masked before comparison
+	Exclusions    *Exclusions      // Excluded keys of factors (Default: DefaultExclusions() ,nil => nothing excluded)
+	Tolerance     Tolerance        // Allowed difference of ContentLength ,Words ,Lines and Timing
+	Custom        []FactorComparer // Custom factors compared after builtin factors (use FactorOf() to ignore)
+	// If not nil responses are compared with learned baseline instead of Original
+	// (unstable factors of baseline are ignored along with Ignore and learned BodyThreshold is used)
+	Baseline         *Baseline
+	IncludeUnchanged bool // Also return responses without changes
+	Ordered          bool // Stream results in input order (Compare results are always sorted)
 }
 
+// One2ManyResults : Result of comparing one response with original
 type One2ManyResults struct {
 	Resp    *rawhttp.RawHttpResponse
-	Changes []Change
+	Changes []Change // Empty if response is same as original
+	Index   int      // Index of response in Many (or order in which it was received by Stream)
+	ID      string   // ID of streamed item (empty for Many)
+	Err     error    // Error while comparing (ex: missing response)
+}
+
+// Item : Response streamed to comparer with optional ID (ex: payload ,url)
+type Item struct {
+	ID   string
+	Resp *rawhttp.RawHttpResponse
 }
 
+// Compare : Compare all responses in Many with Original
+// Results are sorted by Index . If context is cancelled results compared till then are returned
 func (c *One2ManyResponseComparer) Compare(ctx context.Context) []One2ManyResults {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	type Sender struct {
-		Orig   *rawhttp.RawHttpResponse
-		New    *rawhttp.RawHttpResponse
-		Ignore map[Factor]bool
-	}
+	in := make(chan Item)
+	go func() {
+		defer close(in)
+		for _, v := range c.Many {
+			select {
+			case <-ctx.Done():
+				return
+			case in <- Item{Resp: v}:
+			}
+		}
+	}()
 
 	results := []One2ManyResults{}
+	for v := range c.Stream(ctx, in) {
+		results = append(results, v)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Index < results[j].Index
+	})
+	return results
+}
 
-	var senderch chan Sender = make(chan Sender, len(c.Many))
-	var recv chan One2ManyResults = make(chan One2ManyResults)
+// Stream : Compare responses received from channel with Original
+// Results are sent as soon as they are available (or in input order if Ordered is true)
+// Output channel is closed after input channel is closed and all responses are compared
+// or when context is cancelled . Caller must read output until it is closed or cancel context
+func (c *One2ManyResponseComparer) Stream(ctx context.Context, in <-chan Item) <-chan One2ManyResults {
+	concurrency := c.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
 
-	wg := &sync.WaitGroup{}
-	rwg := &sync.WaitGroup{}
+	type job struct {
+		index int
+		item  Item
+	}
 
-	// First launch reciever
-	rwg.Add(1)
-	go func(rch <-chan One2ManyResults, ctx context.Context) {
-		defer rwg.Done()
+	jobs := make(chan job)
+	compared := make(chan One2ManyResults)
+	out := make(chan One2ManyResults)
 
-		for {
+	// tag responses with index in order of arrival
+	go func() {
+		defer close(jobs)
+		for index := 0; ; index++ {
+			var item Item
+			var ok bool
 			select {
 			case <-ctx.Done():
 				return
-
-			case val, ok := <-rch:
+			case item, ok = <-in:
 				if !ok {
 					return
 				}
-				results = append(results, val)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case jobs <- job{index: index, item: item}:
 			}
 		}
+	}()
 
-	}(recv, ctx)
-
-	//create worker thread
-	worker := func(sch <-chan Sender, recv chan<- One2ManyResults, ctx context.Context) {
-		defer wg.Done()
-
-		for {
+	// workers never block after cancellation
+	wg := &sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res := One2ManyResults{
+					Resp:  j.item.Resp,
+					Index: j.index,
+					ID:    j.item.ID,
+				}
+				res.Changes, res.Err = c.compare(j.item.Resp)
+				select {
+				case <-ctx.Done():
+					return
+				case compared <- res:
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(compared)
+	}()
+
+	// send results (unchanged responses are skipped unless IncludeUnchanged is set)
+	go func() {
+		defer close(out)
+		emit := func(res One2ManyResults) bool {
+			if len(res.Changes) == 0 && res.Err == nil && !c.IncludeUnchanged {
+				return true
+			}
 			select {
 			case <-ctx.Done():
-				return
+				return false
+			case out <- res:
+				return true
+			}
+		}
 
-			case val, ok := <-sch:
-				if !ok {
+		pending := map[int]One2ManyResults{}
+		next := 0
+		for res := range compared {
+			if !c.Ordered {
+				if !emit(res) {
 					return
 				}
-				d := NewDualResponseComparer(val.Orig, val.New)
-				d.Ignore = val.Ignore
-				res, _ := d.Compare()
-				if len(res) > 0 {
-					recv <- One2ManyResults{
-						Resp:    val.New,
-						Changes: res,
-					}
+				continue
+			}
+			pending[res.Index] = res
+			for {
+				res, ok := pending[next]
+				if !ok {
+					break
+				}
+				delete(pending, next)
+				next++
+				if !emit(res) {
+					return
 				}
 			}
 		}
+	}()
 
-	}
-
-	// launch threads based on concurrency
-	for i := 0; i < c.Concurrency; i++ {
-		wg.Add(1)
-		go worker(senderch, recv, ctx)
-	}
+	return out
+}
 
-	for _, v := range c.Many {
-		senderch <- Sender{
-			Orig:   c.Original,
-			New:    v,
-			Ignore: c.Ignore,
+// compare : compare single response with original (or baseline)
+func (c *One2ManyResponseComparer) compare(new *rawhttp.RawHttpResponse) ([]Change, error) {
+	var d *DualResponseComparer
+	if c.Baseline != nil {
+		d = c.Baseline.Comparer(new)
+		for k, v := range c.Ignore {
+			d.Ignore[k] = d.Ignore[k] || v
 		}
+	} else {
+		d = NewDualResponseComparer(c.Original, new)
+		d.Ignore = c.Ignore
+		d.BodyThreshold = c.BodyThreshold
 	}
-
-	close(senderch)
-	wg.Wait()
-	close(recv)
-	rwg.Wait()
-
-	return results
+	d.DiffOptions = c.DiffOptions
+	d.Normalizer = c.Normalizer
+	d.Exclusions = c.Exclusions
+	d.Tolerance = c.Tolerance
+	d.Custom = c.Custom
+	return d.Compare()
 }
 
 func NewOne2ManyResponseComparer(original *rawhttp.RawHttpResponse, many ...*rawhttp.RawHttpResponse) *One2ManyResponseComparer {
 	return &One2ManyResponseComparer{
 		Original:    original,
 		Many:        many,
-		Ignore:      map[Factor]bool{HeaderValue: true},
+		Ignore:      DefaultIgnore(),
+		Exclusions:  DefaultExclusions(),
 		Concurrency: runtime.NumCPU(),
 	}
 }
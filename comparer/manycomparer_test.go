@@ -85,3 +85,94 @@ func Test_ManyComparer(t *testing.T) {
 		t.Errorf("Combinations missed only got %v  responses", len(res))
 	}
 }
+
+func Test_Stream(t *testing.T) {
+	parse := func(status string) *rawhttp.RawHttpResponse {
+		resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 " + status + "\r\nContent-Type: text/plain\r\n\r\nhello"))
+		if err != nil {
+			t.Fatalf("failed to parse response %v", err)
+		}
+		return resp
+	}
+	ok, forbidden := parse("200 OK"), parse("403 Forbidden")
+
+	items := []comparer.Item{}
+	for i := 0; i < 100; i++ {
+		resp := ok
+		if i%10 == 3 {
+			resp = forbidden
+		}
+		items = append(items, comparer.Item{ID: fmt.Sprintf("payload%v", i), Resp: resp})
+	}
+	feed := func(ctx context.Context) <-chan comparer.Item {
+		in := make(chan comparer.Item)
+		go func() {
+			defer close(in)
+			for _, v := range items {
+				select {
+				case <-ctx.Done():
+					return
+				case in <- v:
+				}
+			}
+		}()
+		return in
+	}
+
+	m := comparer.NewOne2ManyResponseComparer(ok)
+	m.Concurrency = 8
+
+	// only changed responses ,tagged with index & id
+	count := 0
+	for res := range m.Stream(context.Background(), feed(context.Background())) {
+		count++
+		if res.Index%10 != 3 || res.ID != fmt.Sprintf("payload%v", res.Index) || res.Changes[0].Type != comparer.StatusCode {
+			t.Errorf("unexpected result %v %v %v", res.Index, res.ID, res.Changes)
+		}
+	}
+	if count != 10 {
+		t.Errorf("expected 10 changed responses got %v", count)
+	}
+
+	// ordered with unchanged entries
+	m.IncludeUnchanged = true
+	m.Ordered = true
+	next := 0
+	for res := range m.Stream(context.Background(), feed(context.Background())) {
+		if res.Index != next {
+			t.Fatalf("expected index %v got %v", next, res.Index)
+		}
+		if (len(res.Changes) > 0) != (res.Index%10 == 3) {
+			t.Errorf("unexpected changes for %v %v", res.Index, res.Changes)
+		}
+		next++
+	}
+	if next != 100 {
+		t.Errorf("expected 100 results got %v", next)
+	}
+
+	// cancellation while results are not read must not deadlock
+	ctx, cancel := context.WithCancel(context.Background())
+	out := m.Stream(ctx, feed(ctx))
+	<-out
+	cancel()
+	done := make(chan struct{})
+	go func() {
+		for range out {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("stream did not close after cancellation")
+	}
+
+	// nil responses are reported with error
+	m.IncludeUnchanged = false
+	m.Many = []*rawhttp.RawHttpResponse{ok, nil, forbidden}
+	res := m.Compare(context.Background())
+	if len(res) != 2 || res[0].Index != 1 || res[0].Err == nil || res[1].Index != 2 {
+		t.Errorf("unexpected results %v", res)
+	}
+}
@@ -0,0 +1,210 @@
+package comparer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+/*
+Dynamic Content Normalization
+
+Timestamps ,csrf tokens ,nonces ,session/request ids and reflected
+input change on every request and are reported as changes by comparer
+Normalizer masks these values in body ,header values and location
+before comparison
+
+1. Reflected values (and their url/html/json encoded forms) are replaced with {{reflected}}
+2. Matches of each rule are replaced with {{rulename}}
+   If rule has capturing groups only first group is masked
+   (ex: value of csrf input is masked but not input tag itself)
+
+Rules are applied in order . Reflected values are masked first
+Normalization is only done on copies . Original responses are not modified
+*/
+
+// Rule : Normalization rule (matches are masked)
+type Rule struct {
+	Name  string
+	Regex *regexp.Regexp
+}
+
+// MinReflectedLength : Reflected values shorter than this are not masked
+var MinReflectedLength = 4
+
+// DefaultRules : Builtin normalization rules
+var DefaultRules = []Rule{
+	{"csrf", regexp.MustCompile(`(?i)(?:csrf|xsrf|authenticity_token|requestverificationtoken|_token|nonce)[^>]{0,64}?(?:value|content)\s*=\s*["']([^"']+)`)},
+	{"csrf", regexp.MustCompile(`(?i)"(?:csrf_?token|xsrf_?token|_token|nonce|request_?id|trace_?id)"\s*:\s*"([^"]*)"`)},
+	{"nonce", regexp.MustCompile(`(?i)nonce\s*=\s*["']?([\w+/=-]+)|'nonce-([\w+/=-]+)'`)},
+	{"jwt", regexp.MustCompile(`eyJ[\w-]+\.eyJ[\w-]+\.[\w-]+`)},
+	{"uuid", regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)},
+	{"timestamp", regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?`)},
+	{"timestamp", regexp.MustCompile(`\b(?:Mon|Tue|Wed|Thu|Fri|Sat|Sun), \d{2} (?:Jan|Feb|Mar|Apr|May|Jun|Jul|Aug|Sep|Oct|Nov|Dec) \d{4} \d{2}:\d{2}:\d{2} GMT`)},
+	// unix timestamp (2014-2033) in seconds/milliseconds only as value of time like keys (ex: "ts":1672671845)
+	{"timestamp", regexp.MustCompile(`(?i)(?:time|ts|date|expires?|exp|iat|nbf|created|updated|_at)\w*["']?\s*[:=]\s*["']?(1[4-9]\d{8}(?:\d{3})?)\b`)},
+	{"token", regexp.MustCompile(`\b[0-9a-fA-F]{16,}\b`)}, // session ids ,request ids ,hashes
+	{"cachebuster", regexp.MustCompile(`(?i)[?&](?:_|v|t|ts|cb|cachebuster|timestamp|rand)=([\w.-]+)`)},
+}
+
+// Normalizer : Mask dynamic content before comparison
+type Normalizer struct {
+	Rules     []Rule
+	Reflected []string // Values to mask wherever they are reflected
+}
+
+// AddRule : Add regex rule (matches are replaced with {{name}})
+func (n *Normalizer) AddRule(name string, pattern string) error {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return fmt.Errorf("invalid normalization rule %v: %v", name, err)
+	}
+	n.Rules = append(n.Rules, Rule{Name: name, Regex: re})
+	return nil
+}
+
+// AddReflected : Add values to mask if they are reflected in responses
+func (n *Normalizer) AddReflected(values ...string) {
+	n.Reflected = append(n.Reflected, values...)
+}
+
+// AddRequest : Mask values of query parameters ,cookies and body fields of this request
+// Header values (Host ,User-Agent etc) and path segments are not masked since they
+// are same for all requests and also appear in links . Use AddReflected for those
+// Add both baseline and new request if their responses are compared
+func (n *Normalizer) AddRequest(req *rawhttp.RawHttpRequest) {
+	for _, p := range req.InsertionPoints() {
+		switch p.Type {
+		case rawhttp.PathInsertion, rawhttp.HeaderInsertion:
+			continue
+		}
+		n.Reflected = append(n.Reflected, p.Value)
+	}
+}
+
+// Normalize : Mask dynamic content of data
+func (n *Normalizer) Normalize(data []byte) []byte {
+	for _, v := range n.reflectedVariants() {
+		data = bytes.ReplaceAll(data, []byte(v), []byte("{{reflected}}"))
+	}
+	for _, r := range n.Rules {
+		data = maskRule(data, r)
+	}
+	return data
+}
+
+// NormalizeString : Mask dynamic content of string
+func (n *Normalizer) NormalizeString(s string) string {
+	return string(n.Normalize([]byte(s)))
+}
+
+// NormalizeResponse : Copy of response with normalized body ,header values and location
+// ContentLength of copy is always length of normalized body (not the wire length)
+// so that normalized responses are measured the same way
+func (n *Normalizer) NormalizeResponse(resp *rawhttp.RawHttpResponse) *rawhttp.RawHttpResponse {
+	normalized := *resp
+
+	normalized.Body = n.Normalize(resp.Body)
+	normalized.ContentLength = len(normalized.Body)
+	normalized.Location = n.NormalizeString(resp.Location)
+
+	normalized.Headers = http.Header{}
+	for k, values := range resp.Headers {
+		for _, v := range values {
+			normalized.Headers[k] = append(normalized.Headers[k], n.NormalizeString(v))
+		}
+	}
+
+	return &normalized
+}
+
+// reflectedVariants : reflected values and their encoded forms (longest first)
+func (n *Normalizer) reflectedVariants() []string {
+	unique := map[string]bool{}
+	for _, v := range n.Reflected {
+		if len(v) < MinReflectedLength {
+			continue
+		}
+		unique[v] = true
+		unique[url.QueryEscape(v)] = true
+		unique[url.PathEscape(v)] = true
+		unique[html.EscapeString(v)] = true
+
+		if bin, err := json.Marshal(v); err == nil {
+			unique[strings.Trim(string(bin), `"`)] = true
+		}
+		var buff bytes.Buffer
+		enc := json.NewEncoder(&buff)
+		enc.SetEscapeHTML(false)
+		if enc.Encode(v) == nil {
+			unique[strings.Trim(strings.TrimSpace(buff.String()), `"`)] = true
+		}
+	}
+
+	variants := []string{}
+	for v := range unique {
+		variants = append(variants, v)
+	}
+	// longer values first so that encoded forms are not partially replaced
+	sort.Slice(variants, func(i, j int) bool {
+		if len(variants[i]) != len(variants[j]) {
+			return len(variants[i]) > len(variants[j])
+		}
+		return variants[i] < variants[j]
+	})
+	return variants
+}
+
+// maskRule : replace matches (or first matched group) with {{name}}
+func maskRule(data []byte, r Rule) []byte {
+	matches := r.Regex.FindAllSubmatchIndex(data, -1)
+	if len(matches) == 0 {
+		return data
+	}
+
+	mask := []byte("{{" + r.Name + "}}")
+	res := []byte{}
+	last := 0
+	for _, m := range matches {
+		start, end := m[0], m[1]
+		// first group which participated in match
+		for i := 2; i+1 < len(m); i += 2 {
+			if m[i] >= 0 {
+				start, end = m[i], m[i+1]
+				break
+			}
+		}
+		res = append(res, data[last:start]...)
+		res = append(res, mask...)
+		last = end
+	}
+	res = append(res, data[last:]...)
+
+	return res
+}
+
+// Clone : Copy of normalizer (rules are shared ,reflected values are copied)
+func (n *Normalizer) Clone() *Normalizer {
+	return &Normalizer{
+		Rules:     append([]Rule{}, n.Rules...),
+		Reflected: append([]string{}, n.Reflected...),
+	}
+}
+
+// NewNormalizer : Normalizer with builtin rules
+func NewNormalizer() *Normalizer {
+	rules := make([]Rule, len(DefaultRules))
+	copy(rules, DefaultRules)
+	return &Normalizer{
+		Rules:     rules,
+		Reflected: []string{},
+	}
+}
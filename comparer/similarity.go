@@ -0,0 +1,97 @@
+package comparer
+
+import (
+	"bytes"
+	"unicode"
+	"unicode/utf8"
+)
+
+/*
+Body Similarity
+
+Bodies are split into tokens (words ,numbers and individual symbols)
+and compared using weighted jaccard index of token counts
+
+	similarity = sum(min(countA ,countB)) / sum(max(countA ,countB))
+
+It runs in linear time and unlike token-set jaccard repeated tokens
+are taken into account (ex: one extra row in a table reduces similarity)
+
+1 => identical token counts , 0 => nothing in common
+*/
+
+// Similarity : Similarity ratio (0-1) of two bodies
+func Similarity(a []byte, b []byte) float64 {
+	if bytes.Equal(a, b) {
+		return 1
+	}
+	return weightedJaccard(tokenCounts(a), tokenCounts(b))
+}
+
+// tokenCounts : number of occurrences of each token
+func tokenCounts(data []byte) map[string]int {
+	counts := map[string]int{}
+	for _, t := range tokenize(data) {
+		counts[t]++
+	}
+	return counts
+}
+
+// weightedJaccard : sum(min(countA ,countB)) / sum(max(countA ,countB))
+func weightedJaccard(a map[string]int, b map[string]int) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	common, union := 0, 0
+	for t, ca := range a {
+		cb := b[t]
+		if ca < cb {
+			common += ca
+			union += cb
+		} else {
+			common += cb
+			union += ca
+		}
+	}
+	for t, cb := range b {
+		if _, ok := a[t]; !ok {
+			union += cb
+		}
+	}
+
+	if union == 0 {
+		return 1
+	}
+	return float64(common) / float64(union)
+}
+
+// tokenize : split into words and symbols (whitespace is ignored)
+func tokenize(data []byte) []string {
+	tokens := []string{}
+	start := -1
+
+	for i := 0; i < len(data); {
+		r, size := utf8.DecodeRune(data[i:])
+		word := unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+
+		if word {
+			if start < 0 {
+				start = i
+			}
+		} else {
+			if start >= 0 {
+				tokens = append(tokens, string(data[start:i]))
+				start = -1
+			}
+			if !unicode.IsSpace(r) {
+				tokens = append(tokens, string(data[i:i+size]))
+			}
+		}
+		i += size
+	}
+	if start >= 0 {
+		tokens = append(tokens, string(data[start:]))
+	}
+
+	return tokens
+}
@@ -0,0 +1,99 @@
+package comparer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/comparer"
+	"github.com/tarunKoyalwar/goseclibs/comparer/diff"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+func Test_Similarity(t *testing.T) {
+	page := "<html><body><table><tr><td>apple</td></tr><tr><td>banana</td></tr></table></body></html>"
+
+	if s := comparer.Similarity([]byte(page), []byte(page)); s != 1 {
+		t.Errorf("identical bodies must have similarity 1 got %v", s)
+	}
+	if s := comparer.Similarity(nil, nil); s != 1 {
+		t.Errorf("empty bodies must have similarity 1 got %v", s)
+	}
+	if s := comparer.Similarity([]byte("foo bar"), []byte("baz qux")); s != 0 {
+		t.Errorf("unrelated bodies must have similarity 0 got %v", s)
+	}
+
+	// whitespace is ignored
+	if s := comparer.Similarity([]byte("a  b\n c"), []byte("a b c")); s != 1 {
+		t.Errorf("whitespace should be ignored got %v", s)
+	}
+
+	// repeated tokens count (one row less)
+	fewer := strings.Replace(page, "<tr><td>banana</td></tr>", "", 1)
+	s := comparer.Similarity([]byte(page), []byte(fewer))
+	if s >= 1 || s < 0.5 {
+		t.Errorf("unexpected similarity %v", s)
+	}
+	if comparer.Similarity([]byte(fewer), []byte(page)) != s {
+		t.Errorf("similarity must be symmetric")
+	}
+}
+
+func Test_CompareBody(t *testing.T) {
+	parse := func(body string) *rawhttp.RawHttpResponse {
+		resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n" + body))
+		if err != nil {
+			t.Fatalf("failed to parse response %v", err)
+		}
+		return resp
+	}
+
+	// same length but different content
+	old := parse("<p>Welcome guest, please login to continue</p>")
+	new := parse("<p>Welcome admin, here is your secret code</p>")
+	if old.ContentLength != new.ContentLength {
+		t.Fatalf("test bodies must have same length")
+	}
+
+	c := comparer.NewDualResponseComparer(old, new)
+	c.Ignore = map[comparer.Factor]bool{comparer.HeaderValue: true, comparer.BodyHash: true, comparer.Words: true}
+	changes, _ := c.Compare()
+	if len(changes) != 1 || changes[0].Type != comparer.Body {
+		t.Fatalf("expected body change got %v", changes)
+	}
+	if changes[0].Score <= 0 || changes[0].Score >= comparer.DefaultBodyThreshold {
+		t.Errorf("unexpected score %v", changes[0].Score)
+	}
+
+	// threshold
+	c.BodyThreshold = changes[0].Score
+	if changes, _ := c.Compare(); len(changes) != 0 {
+		t.Errorf("expected no changes with lower threshold got %v", changes)
+	}
+
+	// exclusions are removed before comparison
+	c.BodyThreshold = 1
+	c.Exclusions.Add(comparer.Body, "guest, please login to continue", "admin, here is your secret code")
+	if changes, _ := c.Compare(); len(changes) != 0 {
+		t.Errorf("expected no changes after exclusions got %v", changes)
+	}
+}
+
+func Test_BodyDiff(t *testing.T) {
+	old, _ := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n<html>\n<p>Welcome guest</p>\n</html>\n"))
+	new, _ := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nContent-Type: text/html\r\n\r\n<html>\n<p>Welcome admin</p>\n</html>\n"))
+
+	c := comparer.NewDualResponseComparer(old, new)
+	c.Ignore = map[comparer.Factor]bool{comparer.HeaderValue: true, comparer.BodyHash: true}
+	c.BodyThreshold = 1
+	c.DiffOptions = diff.DefaultOptions()
+
+	expected := "--- old\n+++ new\n@@ -1,3 +1,3 @@\n <html>\n-<p>Welcome guest</p>\n+<p>Welcome admin</p>\n </html>\n"
+
+	changes, _ := c.Compare()
+	if len(changes) != 1 || changes[0].Diff != expected {
+		t.Fatalf("unexpected changes %v", changes)
+	}
+	if got := c.BodyDiff(nil); got != expected {
+		t.Errorf("unexpected body diff\n%v", got)
+	}
+}
@@ -2,6 +2,10 @@ module github.com/tarunKoyalwar/goseclibs
 
 go 1.18
 
-require go.uber.org/ratelimit v0.2.0
+require (
+	go.uber.org/ratelimit v0.2.0
+	golang.org/x/net v0.30.0
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require github.com/andres-erbsen/clock v0.0.0-20160526145045-9e14626cd129 // indirect
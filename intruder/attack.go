@@ -0,0 +1,433 @@
+package intruder
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/tarunKoyalwar/goseclibs/comparer"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/encode"
+)
+
+/*
+Intruder Style Attacks using rawhttp.RequestTemplate
+
+Attack Types (Same as Burpsuite Intruder)
+1. Sniper       => One wordlist . Each position is fuzzed one at a time (others use default value)
+2. BatteringRam => One wordlist . Same payload is placed in all positions
+3. Pitchfork    => One wordlist per position . Wordlists are iterated in parallel
+4. ClusterBomb  => One wordlist per position . All combinations of wordlists
+
+Payload Processing
+Processing pipeline of a position is applied on wordlist of that position
+(BatteringRam places same payload everywhere and only uses pipeline of position 0)
+Pitchfork applies pipelines row by row . If a pipeline returns multiple payloads
+for a row all combinations of that row are generated
+
+Baseline Request (template with default values) is sent first and
+all responses are compared against it using comparer.One2ManyResponseComparer
+*/
+
+// AttackType : Type of Intruder Attack
+type AttackType int
+
+const (
+	Sniper AttackType = iota
+	BatteringRam
+	Pitchfork
+	ClusterBomb
+)
+
+// AttackTypeString : Name of attack type
+func AttackTypeString(z AttackType) string {
+	switch z {
+	case Sniper:
+		return "Sniper"
+	case BatteringRam:
+		return "BatteringRam"
+	case Pitchfork:
+		return "Pitchfork"
+	case ClusterBomb:
+		return "ClusterBomb"
+	default:
+		return "Invalid"
+	}
+}
+
+// Attack : Intruder Attack
+type Attack struct {
+	Template    *rawhttp.RequestTemplate
+	Type        AttackType
+	Payloads    [][]string               // Wordlists (Sniper & BatteringRam only use first wordlist)
+	Processing  map[int]encode.Pipeline  // Payload processing pipeline of each position
+	Client      *rawhttp.SHTTPClient     // Client used to send requests (Default: SHTTPClient with defaults)
+	Concurrency int                      // Number of concurrent requests (Default: NumCPU)
+	Ignore      map[comparer.Factor]bool /* Factors Ignored While Comparing
+	By default all Factors are considered except comparer.DefaultIgnore() (Body is considered)*/
+	BodyThreshold float64 // Minimum body similarity with baseline (Default: comparer.DefaultBodyThreshold)
+	// If not nil dynamic content is masked before comparison
+	// default values and payloads of each request are masked automatically
+	Normalizer *comparer.Normalizer
+	// Number of baseline requests used to learn unstable factors (Default: 1 => no learning)
+	BaselineSamples int
+	Exclusions      *comparer.Exclusions      // Excluded keys while comparing (Default: comparer.DefaultExclusions())
+	Custom          []comparer.FactorComparer // Custom factors compared along with builtin factors
+}
+
+// AttackResult : Result of a single request
+type AttackResult struct {
+	Payloads []string // Payloads at each position
+	Request  *rawhttp.RawHttpRequest
+	Response *rawhttp.RawHttpResponse
+	Changes  []comparer.Change // Changes when compared to baseline
+	Err      error             // Error while generating/sending request
+}
+
+// Count : Total number of requests that will be generated
+func (a *Attack) Count() int {
+	positions := len(a.Template.Positions)
+
+	switch a.Type {
+	case Sniper:
+		total := 0
+		for pos := 0; pos < positions; pos++ {
+			total += len(a.wordlist(pos))
+		}
+		return total
+	case BatteringRam:
+		return len(a.wordlist(0))
+	case Pitchfork:
+		total := 0
+		for _, row := range a.pitchforkRows() {
+			count := 1
+			for _, v := range row {
+				count *= len(v)
+			}
+			total += count
+		}
+		return total
+	case ClusterBomb:
+		if len(a.Payloads) == 0 {
+			return 0
+		}
+		total := 1
+		for pos := range a.Payloads {
+			total *= len(a.wordlist(pos))
+		}
+		return total
+	}
+
+	return 0
+}
+
+// Validate : Check if wordlists are suitable for attack type
+func (a *Attack) Validate() error {
+	if a.Template == nil {
+		return fmt.Errorf("missing request template")
+	}
+
+	switch a.Type {
+	case Sniper, BatteringRam:
+		if len(a.Payloads) == 0 {
+			return fmt.Errorf("%v attack requires a wordlist", AttackTypeString(a.Type))
+		}
+	case Pitchfork, ClusterBomb:
+		if len(a.Payloads) != len(a.Template.Positions) {
+			return fmt.Errorf("%v attack requires %v wordlists but got %v", AttackTypeString(a.Type), len(a.Template.Positions), len(a.Payloads))
+		}
+	default:
+		return fmt.Errorf("invalid attack type %v", a.Type)
+	}
+
+	return nil
+}
+
+// wordlist : processed wordlist of given position
+func (a *Attack) wordlist(pos int) []string {
+	if len(a.Payloads) == 0 {
+		return nil
+	}
+	list := a.Payloads[0]
+	if a.Type == Pitchfork || a.Type == ClusterBomb {
+		if pos >= len(a.Payloads) {
+			return nil
+		}
+		list = a.Payloads[pos]
+	}
+
+	if pipeline, ok := a.Processing[pos]; ok {
+		return pipeline.ApplyAll(list)
+	}
+	return list
+}
+
+// Generate : Generate payload sets for each request in order
+// fn is called for each payload set and generation stops if it returns false
+func (a *Attack) Generate(fn func(payloads []string) bool) error {
+	if err := a.Validate(); err != nil {
+		return err
+	}
+
+	defaults := a.Template.Defaults()
+
+	lists := make([][]string, len(defaults))
+	for pos := range lists {
+		lists[pos] = a.wordlist(pos)
+	}
+
+	switch a.Type {
+	case Sniper:
+		for pos := range defaults {
+			for _, p := range lists[pos] {
+				set := append([]string{}, defaults...)
+				set[pos] = p
+				if !fn(set) {
+					return nil
+				}
+			}
+		}
+
+	case BatteringRam:
+		for _, p := range lists[0] {
+			set := make([]string, len(defaults))
+			for i := range set {
+				set[i] = p
+			}
+			if !fn(set) {
+				return nil
+			}
+		}
+
+	case Pitchfork:
+		for _, row := range a.pitchforkRows() {
+			if !combinations(row, fn) {
+				return nil
+			}
+		}
+
+	case ClusterBomb:
+		combinations(lists, fn)
+	}
+
+	return nil
+}
+
+// pitchforkRows : processed payloads of each position for every row of wordlists
+// pipelines are applied row by row so that payloads of a row stay together
+// (deduplication or variants of one position do not shift other positions)
+func (a *Attack) pitchforkRows() [][][]string {
+	if len(a.Payloads) == 0 {
+		return nil
+	}
+	total := len(a.Payloads[0])
+	for _, v := range a.Payloads {
+		if len(v) < total {
+			total = len(v)
+		}
+	}
+
+	rows := make([][][]string, total)
+	for i := range rows {
+		rows[i] = make([][]string, len(a.Template.Positions))
+		for pos := range rows[i] {
+			if pos >= len(a.Payloads) {
+				continue
+			}
+			payload := a.Payloads[pos][i]
+			if pipeline, ok := a.Processing[pos]; ok {
+				rows[i][pos] = pipeline.Apply(payload)
+			} else {
+				rows[i][pos] = []string{payload}
+			}
+		}
+	}
+	return rows
+}
+
+// combinations : call fn with every combination of lists (last position changes fastest)
+// returns false if fn stopped generation
+func combinations(lists [][]string, fn func(payloads []string) bool) bool {
+	for _, v := range lists {
+		if len(v) == 0 {
+			return true
+		}
+	}
+	// odometer over all lists
+	idx := make([]int, len(lists))
+	for {
+		set := make([]string, len(lists))
+		for pos := range set {
+			set[pos] = lists[pos][idx[pos]]
+		}
+		if !fn(set) {
+			return false
+		}
+
+		pos := len(idx) - 1
+		for ; pos >= 0; pos-- {
+			idx[pos]++
+			if idx[pos] < len(lists[pos]) {
+				break
+			}
+			idx[pos] = 0
+		}
+		if pos < 0 {
+			return true
+		}
+	}
+}
+
+// send : send raw request and parse response
+func send(client *rawhttp.SHTTPClient, req *rawhttp.RawHttpRequest) (*rawhttp.RawHttpResponse, error) {
+	r, err := req.BuildRequest()
+	if err != nil {
+		return nil, err
+	}
+	return client.DoRaw(r)
+}
+
+// Run : Execute attack and compare all responses with baseline
+// Results are returned in the order payloads were generated
+func (a *Attack) Run(ctx context.Context) ([]AttackResult, error) {
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
+	// defaults are not written back to attack
+	client := a.Client
+	if client == nil {
+		client = &rawhttp.SHTTPClient{}
+		client.Create()
+	}
+
+	concurrency := a.Concurrency
+	if concurrency <= 0 {
+		concurrency = runtime.NumCPU()
+	}
+
+	base, err := a.Template.Baseline()
+	if err != nil {
+		return nil, err
+	}
+
+	samples := []*rawhttp.RawHttpResponse{}
+	for len(samples) == 0 || len(samples) < a.BaselineSamples {
+		sample, err := send(client, base)
+		if err != nil {
+			return nil, fmt.Errorf("failed to send baseline request %v", err)
+		}
+		samples = append(samples, sample)
+	}
+	baseline := samples[0]
+
+	type job struct {
+		index    int
+		payloads []string
+	}
+
+	results := make([]AttackResult, a.Count())
+	jobs := make(chan job)
+	wg := &sync.WaitGroup{}
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				res := AttackResult{Payloads: j.payloads}
+				res.Request, res.Err = a.Template.Render(j.payloads)
+				if res.Err == nil {
+					res.Response, res.Err = send(client, res.Request)
+				}
+				// each job owns its index
+				results[j.index] = res
+			}
+		}()
+	}
+
+	count := 0
+	generr := a.Generate(func(payloads []string) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case jobs <- job{index: count, payloads: payloads}:
+			count++
+			return true
+		}
+	})
+
+	close(jobs)
+	wg.Wait()
+
+	if generr != nil {
+		return nil, generr
+	}
+
+	results = results[:count]
+
+	// Compare all responses against baseline
+	responses := []*rawhttp.RawHttpResponse{}
+	index := []int{} // index of result of each response
+	for i, v := range results {
+		if v.Response != nil {
+			resp := v.Response
+			if a.Normalizer != nil {
+				// only payloads differ between requests
+				n := a.Normalizer.Clone()
+				n.AddReflected(a.Template.Defaults()...)
+				n.AddReflected(v.Payloads...)
+				resp = n.NormalizeResponse(resp)
+			}
+			responses = append(responses, resp)
+			index = append(index, i)
+		}
+	}
+
+	if a.Normalizer != nil {
+		n := a.Normalizer.Clone()
+		n.AddReflected(a.Template.Defaults()...)
+		for i := range samples {
+			samples[i] = n.NormalizeResponse(samples[i])
+		}
+		baseline = samples[0]
+	}
+
+	m := comparer.NewOne2ManyResponseComparer(baseline, responses...)
+	if a.Ignore != nil {
+		m.Ignore = a.Ignore
+	}
+	m.Concurrency = concurrency
+	m.BodyThreshold = a.BodyThreshold
+	if a.Exclusions != nil {
+		m.Exclusions = a.Exclusions
+	}
+	m.Custom = a.Custom
+	if len(samples) > 1 {
+		m.Baseline, err = comparer.NewBaseline(samples...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, v := range m.Compare(ctx) {
+		results[index[v.Index]].Changes = v.Changes
+	}
+
+	return results, ctx.Err()
+}
+
+// NewAttack : New Attack using given template
+func NewAttack(template *rawhttp.RequestTemplate, attacktype AttackType, payloads ...[]string) *Attack {
+	ignore := comparer.DefaultIgnore()
+	delete(ignore, comparer.Body)
+	return &Attack{
+		Template:    template,
+		Type:        attacktype,
+		Payloads:    payloads,
+		Ignore:      ignore,
+		Concurrency: runtime.NumCPU(),
+		Exclusions:  comparer.DefaultExclusions(),
+	}
+}
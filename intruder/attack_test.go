@@ -0,0 +1,213 @@
+package intruder_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/comparer"
+	"github.com/tarunKoyalwar/goseclibs/intruder"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/encode"
+)
+
+func Test_AttackTypes(t *testing.T) {
+	tmpl, err := rawhttp.NewRequestTemplate("GET /?a=§x§&b={{FUZZ}} HTTP/1.1\nHost: example.com\n\n")
+	if err != nil {
+		t.Fatalf("failed to parse template %v", err)
+	}
+
+	if len(tmpl.Positions) != 2 || tmpl.Positions[0].Default != "x" {
+		t.Fatalf("unexpected positions %v", tmpl.Positions)
+	}
+
+	cases := []struct {
+		attack   intruder.AttackType
+		payloads [][]string
+		expected string
+	}{
+		{intruder.Sniper, [][]string{{"1", "2"}}, "1,|2,|x,1|x,2"},
+		{intruder.BatteringRam, [][]string{{"1", "2"}}, "1,1|2,2"},
+		{intruder.Pitchfork, [][]string{{"1", "2", "3"}, {"a", "b"}}, "1,a|2,b"},
+		{intruder.ClusterBomb, [][]string{{"1", "2"}, {"a", "b"}}, "1,a|1,b|2,a|2,b"},
+	}
+
+	for _, c := range cases {
+		a := intruder.NewAttack(tmpl, c.attack, c.payloads...)
+		got := []string{}
+		a.Generate(func(p []string) bool {
+			got = append(got, strings.Join(p, ","))
+			return true
+		})
+		if strings.Join(got, "|") != c.expected || a.Count() != len(got) {
+			t.Errorf("%v generated %v", intruder.AttackTypeString(c.attack), got)
+		}
+	}
+}
+
+func Test_AttackRun(t *testing.T) {
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("id") == "admin" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+		fmt.Fprintf(w, "hello")
+	}))
+	defer ts.Close()
+
+	host := strings.TrimPrefix(ts.URL, "https://")
+	tmpl, err := rawhttp.NewRequestTemplate("GET /?id=§guest§ HTTP/1.1\nHost: " + host + "\n\n")
+	if err != nil {
+		t.Fatalf("failed to parse template %v", err)
+	}
+
+	a := intruder.NewAttack(tmpl, intruder.Sniper, []string{"user", "admin", "test"})
+	a.Concurrency = 0
+	results, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("attack failed %v", err)
+	}
+	if a.Client != nil || a.Concurrency != 0 {
+		t.Errorf("defaults written to attack %v %v", a.Client, a.Concurrency)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results got %v", len(results))
+	}
+
+	for i, v := range results {
+		if v.Err != nil {
+			t.Errorf("request %v failed %v", i, v.Err)
+			continue
+		}
+		changed := false
+		for _, c := range v.Changes {
+			if c.Type == comparer.StatusCode {
+				changed = true
+			}
+		}
+		if changed != (v.Payloads[0] == "admin") {
+			t.Errorf("unexpected changes for %v : %v", v.Payloads, v.Changes)
+		}
+	}
+}
+
+func Test_AttackProcessing(t *testing.T) {
+	tmpl, _ := rawhttp.NewRequestTemplate("GET /?a=§x§&b=§y§ HTTP/1.1\nHost: example.com\n\n")
+
+	a := intruder.NewAttack(tmpl, intruder.Sniper, []string{"<a>"})
+	a.Processing = map[int]encode.Pipeline{
+		1: encode.NewPipeline(encode.CaseVariants, encode.Encoder(encode.HTML)),
+	}
+
+	got := []string{}
+	a.Generate(func(p []string) bool {
+		got = append(got, strings.Join(p, ","))
+		return true
+	})
+
+	if strings.Join(got, "|") != "<a>,y|x,&lt;a&gt;|x,&lt;A&gt;" || a.Count() != 3 {
+		t.Errorf("unexpected processed payloads %v", got)
+	}
+}
+
+func Test_AttackNormalizer(t *testing.T) {
+	count := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		fmt.Fprintf(w, "<p>results for %v</p><input name=\"csrf\" value=\"token%v\">", r.URL.Query().Get("q"), count)
+	}))
+	defer ts.Close()
+
+	host := strings.TrimPrefix(ts.URL, "https://")
+	tmpl, err := rawhttp.NewRequestTemplate("GET /?q=§guest§ HTTP/1.1\nHost: " + host + "\n\n")
+	if err != nil {
+		t.Fatalf("failed to parse template %v", err)
+	}
+
+	a := intruder.NewAttack(tmpl, intruder.Sniper, []string{"someone", "<script>alert(1)</script>"})
+	a.Concurrency = 1
+	a.BodyThreshold = 1
+	a.Normalizer = comparer.NewNormalizer()
+
+	results, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("attack failed %v", err)
+	}
+	for _, v := range results {
+		if v.Err != nil || len(v.Changes) != 0 {
+			t.Errorf("unexpected changes for %v : %v %v", v.Payloads, v.Changes, v.Err)
+		}
+	}
+}
+
+func Test_AttackBaseline(t *testing.T) {
+	count := 0
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count++
+		w.Header().Set("X-Request-Count", fmt.Sprint(count))
+		if r.URL.Query().Get("debug") == "true" {
+			fmt.Fprintf(w, "<p>debug enabled</p>\n")
+		}
+		fmt.Fprintf(w, "<p>page views: %v</p>\n", count*11)
+	}))
+	defer ts.Close()
+
+	host := strings.TrimPrefix(ts.URL, "https://")
+	tmpl, err := rawhttp.NewRequestTemplate("GET /?debug=§false§ HTTP/1.1\nHost: " + host + "\n\n")
+	if err != nil {
+		t.Fatalf("failed to parse template %v", err)
+	}
+
+	a := intruder.NewAttack(tmpl, intruder.Sniper, []string{"no", "true", "yes"})
+	a.Concurrency = 1
+	a.Ignore = map[comparer.Factor]bool{}
+	a.BaselineSamples = 3
+
+	results, err := a.Run(context.Background())
+	if err != nil {
+		t.Fatalf("attack failed %v", err)
+	}
+	for _, v := range results {
+		if v.Err != nil {
+			t.Fatalf("request failed %v", v.Err)
+		}
+		if (len(v.Changes) > 0) != (v.Payloads[0] == "true") {
+			t.Errorf("unexpected changes for %v : %v", v.Payloads, v.Changes)
+		}
+	}
+}
+
+func Test_AttackPitchforkProcessing(t *testing.T) {
+	tmpl, _ := rawhttp.NewRequestTemplate("GET /?user=§x§&pass=§y§ HTTP/1.1\nHost: example.com\n\n")
+
+	// Lower makes first two usernames same (deduplicated by pipeline)
+	a := intruder.NewAttack(tmpl, intruder.Pitchfork, []string{"Admin", "admin", "root"}, []string{"p1", "p2", "p3"})
+	a.Processing = map[int]encode.Pipeline{
+		0: encode.NewPipeline(encode.Encoder(encode.Lower)),
+	}
+
+	got := []string{}
+	a.Generate(func(p []string) bool {
+		got = append(got, strings.Join(p, ","))
+		return true
+	})
+	if strings.Join(got, "|") != "admin,p1|admin,p2|root,p3" || a.Count() != 3 {
+		t.Errorf("pairing of positions changed %v", got)
+	}
+
+	// multiple variants only expand their own row
+	a.Processing = map[int]encode.Pipeline{
+		1: encode.NewPipeline(encode.CaseVariants),
+	}
+	got = []string{}
+	a.Generate(func(p []string) bool {
+		got = append(got, strings.Join(p, ","))
+		return true
+	})
+	if strings.Join(got, "|") != "Admin,p1|Admin,P1|admin,p2|admin,P2|root,p3|root,P3" || a.Count() != len(got) {
+		t.Errorf("unexpected variants %v (count %v)", got, a.Count())
+	}
+}
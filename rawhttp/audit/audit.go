@@ -0,0 +1,438 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+/*
+Security Header & Cookie Policy Auditing
+
+Checks response for missing or weak
+1. Strict-Transport-Security (max-age ,includeSubDomains ,preload)
+2. Content-Security-Policy (unsafe-inline ,unsafe-eval ,wildcards ,missing directives)
+3. X-Frame-Options / frame-ancestors
+4. X-Content-Type-Options ,Referrer-Policy ,Permissions-Policy
+5. CORS (Access-Control-Allow-*)
+6. Cookie Flags (Secure ,HttpOnly ,SameSite ,__Secure-/__Host- prefixes)
+
+Browser only headers (CSP ,framing ,Referrer-Policy ,Permissions-Policy)
+are only checked for html responses (or responses without content-type)
+
+Scheme is taken from original request stored in response or from
+RawHttpRequest.Scheme of given request . Otherwise response is treated as https
+
+Findings are grouped in a Report which can be exported as JSON or CSV
+*/
+
+// Severity : Severity of finding
+type Severity int
+
+const (
+	Info Severity = iota
+	Low
+	Medium
+	High
+)
+
+// SeverityString : Name of severity
+func SeverityString(z Severity) string {
+	switch z {
+	case Info:
+		return "info"
+	case Low:
+		return "low"
+	case Medium:
+		return "medium"
+	case High:
+		return "high"
+	default:
+		return "invalid"
+	}
+}
+
+// MarshalText : severity is exported by name
+func (z Severity) MarshalText() ([]byte, error) {
+	return []byte(SeverityString(z)), nil
+}
+
+// UnmarshalText : parse severity name
+func (z *Severity) UnmarshalText(text []byte) error {
+	for s := Info; s <= High; s++ {
+		if strings.EqualFold(SeverityString(s), string(text)) {
+			*z = s
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %v", string(text))
+}
+
+// Finding : Missing or weak security policy
+type Finding struct {
+	ID       string   `json:"id"`       // Stable id (ex: hsts-missing)
+	Category string   `json:"category"` // HSTS ,CSP ,Framing ,CORS ,Cookie etc
+	Severity Severity `json:"severity"`
+	Title    string   `json:"title"`
+	Header   string   `json:"header,omitempty"` // Header which caused finding
+	Cookie   string   `json:"cookie,omitempty"` // Cookie name (for cookie findings)
+	Value    string   `json:"value,omitempty"`  // Offending value (if any)
+	Source   string   `json:"source,omitempty"` // URL of response (if known)
+}
+
+// Report : Audit findings of a response
+type Report struct {
+	Target   string    `json:"target,omitempty"`
+	Findings []Finding `json:"findings"`
+}
+
+// Auditor : Security header & cookie auditor
+type Auditor struct {
+	MinHSTSMaxAge  int      // Minimum HSTS max-age in seconds (Default: 180 days)
+	CSPDirectives  []string // Directives which should be present in CSP
+	SessionCookies []string // Substrings of session cookie names (missing HttpOnly is more severe)
+}
+
+// NewAuditor : Auditor with default configuration
+func NewAuditor() *Auditor {
+	return &Auditor{
+		MinHSTSMaxAge:  15552000,
+		CSPDirectives:  []string{"default-src", "object-src", "base-uri", "frame-ancestors"},
+		SessionCookies: []string{"sess", "sid", "auth", "token", "jwt", "login"},
+	}
+}
+
+// Audit : Audit security headers and cookies of response
+func (a *Auditor) Audit(r *rawhttp.RawHttpResponse) *Report {
+	return a.AuditRequest(nil, r)
+}
+
+// AuditRequest : Audit response of given request
+// Origin header of request (if any) is used to detect reflected CORS origins
+func (a *Auditor) AuditRequest(req *rawhttp.RawHttpRequest, r *rawhttp.RawHttpResponse) *Report {
+	rep := &Report{Findings: []Finding{}}
+	https := true
+
+	if r.Response != nil && r.Response.Request != nil && r.Response.Request.URL != nil {
+		rep.Target = r.Response.Request.URL.String()
+		https = r.Response.Request.URL.Scheme != "http"
+	} else if req != nil {
+		rep.Target = req.RawURL
+		https = req.Scheme != "http"
+	}
+
+	headers := r.Headers
+	if headers == nil {
+		headers = http.Header{}
+	}
+
+	if https {
+		rep.Findings = append(rep.Findings, a.auditHSTS(headers)...)
+	}
+
+	if nosniff := headers.Get("X-Content-Type-Options"); !strings.EqualFold(strings.TrimSpace(nosniff), "nosniff") {
+		f := Finding{ID: "nosniff-missing", Category: "Content-Type-Options", Severity: Low, Title: "X-Content-Type-Options is not set to nosniff", Header: "X-Content-Type-Options"}
+		if nosniff != "" {
+			f.ID, f.Value = "nosniff-invalid", nosniff
+		}
+		rep.Findings = append(rep.Findings, f)
+	}
+
+	if isHTML(r.ContentType) {
+		rep.Findings = append(rep.Findings, a.auditCSP(headers)...)
+		rep.Findings = append(rep.Findings, a.auditFraming(headers)...)
+		rep.Findings = append(rep.Findings, a.auditReferrer(headers)...)
+		rep.Findings = append(rep.Findings, a.auditPermissions(headers)...)
+	}
+
+	origin := ""
+	if req != nil {
+		origin = req.Headers["origin"]
+	}
+	rep.Findings = append(rep.Findings, a.auditCORS(headers, origin)...)
+
+	rep.Findings = append(rep.Findings, a.auditCookies(r.Cookies, https)...)
+
+	for i := range rep.Findings {
+		rep.Findings[i].Source = rep.Target
+	}
+	rep.Sort()
+
+	return rep
+}
+
+// Sort : Sort findings by severity (highest first) and id
+func (r *Report) Sort() {
+	sort.SliceStable(r.Findings, func(i, j int) bool {
+		if r.Findings[i].Severity != r.Findings[j].Severity {
+			return r.Findings[i].Severity > r.Findings[j].Severity
+		}
+		if r.Findings[i].ID != r.Findings[j].ID {
+			return r.Findings[i].ID < r.Findings[j].ID
+		}
+		return r.Findings[i].Cookie < r.Findings[j].Cookie
+	})
+}
+
+// Filter : Findings with severity greater than or equal to min
+func (r *Report) Filter(min Severity) []Finding {
+	findings := []Finding{}
+	for _, f := range r.Findings {
+		if f.Severity >= min {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// Has : Check if report contains finding with given id
+func (r *Report) Has(id string) bool {
+	for _, f := range r.Findings {
+		if f.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON : Export report as JSON
+func (r *Report) JSON() ([]byte, error) {
+	return json.MarshalIndent(r, "", "  ")
+}
+
+// CSV : Export findings of multiple reports as CSV (with header row)
+func CSV(w io.Writer, reports ...*Report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"source", "severity", "category", "id", "title", "header", "cookie", "value"}); err != nil {
+		return err
+	}
+	for _, r := range reports {
+		for _, f := range r.Findings {
+			row := []string{f.Source, SeverityString(f.Severity), f.Category, f.ID, f.Title, f.Header, f.Cookie, f.Value}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// CSV : Export report as CSV
+func (r *Report) CSV() ([]byte, error) {
+	var buff bytes.Buffer
+	err := CSV(&buff, r)
+	return buff.Bytes(), err
+}
+
+func (a *Auditor) auditHSTS(headers http.Header) []Finding {
+	value := headers.Get("Strict-Transport-Security")
+	if value == "" {
+		return []Finding{{ID: "hsts-missing", Category: "HSTS", Severity: Medium, Title: "Strict-Transport-Security header is missing", Header: "Strict-Transport-Security"}}
+	}
+
+	findings := []Finding{}
+	add := func(id string, sev Severity, title string) {
+		findings = append(findings, Finding{ID: id, Category: "HSTS", Severity: sev, Title: title, Header: "Strict-Transport-Security", Value: value})
+	}
+
+	maxage := -1
+	subdomains, preload := false, false
+	for _, d := range strings.Split(value, ";") {
+		kv := strings.SplitN(strings.TrimSpace(d), "=", 2)
+		switch strings.ToLower(kv[0]) {
+		case "max-age":
+			if len(kv) == 2 {
+				if v, err := strconv.Atoi(strings.Trim(kv[1], `" `)); err == nil {
+					maxage = v
+				}
+			}
+		case "includesubdomains":
+			subdomains = true
+		case "preload":
+			preload = true
+		}
+	}
+
+	switch {
+	case maxage < 0:
+		add("hsts-invalid-max-age", Medium, "HSTS max-age is missing or invalid")
+	case maxage == 0:
+		add("hsts-disabled", Medium, "HSTS is disabled using max-age=0")
+	case maxage < a.MinHSTSMaxAge:
+		add("hsts-short-max-age", Low, fmt.Sprintf("HSTS max-age %v is less than %v", maxage, a.MinHSTSMaxAge))
+	}
+
+	if !subdomains {
+		add("hsts-no-subdomains", Info, "HSTS does not include subdomains")
+	}
+
+	if !preload {
+		add("hsts-no-preload", Info, "HSTS preload is not enabled")
+	} else if maxage < 31536000 || !subdomains {
+		// https://hstspreload.org/#submission-requirements
+		add("hsts-preload-invalid", Low, "HSTS preload requires max-age of at least 1 year and includeSubDomains")
+	}
+
+	return findings
+}
+
+func (a *Auditor) auditFraming(headers http.Header) []Finding {
+	xfo := strings.TrimSpace(headers.Get("X-Frame-Options"))
+	csp := ParseCSP(strings.Join(headers.Values("Content-Security-Policy"), ","))
+
+	if _, ok := csp["frame-ancestors"]; ok {
+		return []Finding{}
+	}
+
+	switch strings.ToUpper(xfo) {
+	case "DENY", "SAMEORIGIN":
+		return []Finding{}
+	case "":
+		return []Finding{{ID: "framing-missing", Category: "Framing", Severity: Medium, Title: "Neither X-Frame-Options nor CSP frame-ancestors is set (clickjacking)", Header: "X-Frame-Options"}}
+	default:
+		// ALLOW-FROM is not supported by modern browsers
+		return []Finding{{ID: "framing-invalid", Category: "Framing", Severity: Medium, Title: "X-Frame-Options value is invalid or unsupported", Header: "X-Frame-Options", Value: xfo}}
+	}
+}
+
+func (a *Auditor) auditReferrer(headers http.Header) []Finding {
+	value := headers.Get("Referrer-Policy")
+	if value == "" {
+		return []Finding{{ID: "referrer-policy-missing", Category: "Referrer-Policy", Severity: Low, Title: "Referrer-Policy header is missing", Header: "Referrer-Policy"}}
+	}
+
+	// last supported value is used by browsers
+	policies := strings.Split(value, ",")
+	policy := strings.ToLower(strings.TrimSpace(policies[len(policies)-1]))
+
+	switch policy {
+	case "unsafe-url", "no-referrer-when-downgrade":
+		return []Finding{{ID: "referrer-policy-unsafe", Category: "Referrer-Policy", Severity: Low, Title: "Referrer-Policy leaks full url to other origins", Header: "Referrer-Policy", Value: value}}
+	case "no-referrer", "origin", "origin-when-cross-origin", "same-origin", "strict-origin", "strict-origin-when-cross-origin":
+		return []Finding{}
+	default:
+		return []Finding{{ID: "referrer-policy-invalid", Category: "Referrer-Policy", Severity: Low, Title: "Referrer-Policy value is invalid", Header: "Referrer-Policy", Value: value}}
+	}
+}
+
+func (a *Auditor) auditPermissions(headers http.Header) []Finding {
+	if headers.Get("Permissions-Policy") != "" {
+		return []Finding{}
+	}
+	if fp := headers.Get("Feature-Policy"); fp != "" {
+		return []Finding{{ID: "permissions-policy-deprecated", Category: "Permissions-Policy", Severity: Info, Title: "Deprecated Feature-Policy is used instead of Permissions-Policy", Header: "Feature-Policy", Value: fp}}
+	}
+	return []Finding{{ID: "permissions-policy-missing", Category: "Permissions-Policy", Severity: Info, Title: "Permissions-Policy header is missing", Header: "Permissions-Policy"}}
+}
+
+func (a *Auditor) auditCORS(headers http.Header, origin string) []Finding {
+	acao := strings.TrimSpace(headers.Get("Access-Control-Allow-Origin"))
+	if acao == "" {
+		return []Finding{}
+	}
+	creds := strings.EqualFold(strings.TrimSpace(headers.Get("Access-Control-Allow-Credentials")), "true")
+
+	add := func(id string, sev Severity, title string) []Finding {
+		return []Finding{{ID: id, Category: "CORS", Severity: sev, Title: title, Header: "Access-Control-Allow-Origin", Value: acao}}
+	}
+
+	switch {
+	case acao == "*" && creds:
+		// browsers reject this combination but it indicates misconfigured server
+		return add("cors-wildcard-credentials", Medium, "CORS allows any origin with credentials")
+	case acao == "*":
+		return add("cors-wildcard", Info, "CORS allows any origin")
+	case strings.EqualFold(acao, "null"):
+		return add("cors-null-origin", Medium, "CORS allows null origin (sandboxed iframes ,local files)")
+	case origin != "" && acao == origin && creds:
+		return add("cors-reflected-origin-credentials", High, "CORS reflects request origin and allows credentials")
+	case origin != "" && acao == origin:
+		return add("cors-reflected-origin", Low, "CORS reflects request origin")
+	case strings.HasPrefix(strings.ToLower(acao), "http://") && creds:
+		return add("cors-insecure-origin", Low, "CORS allows credentials from http origin")
+	}
+
+	return []Finding{}
+}
+
+func (a *Auditor) auditCookies(cookies map[string]*http.Cookie, https bool) []Finding {
+	findings := []Finding{}
+
+	names := make([]string, 0, len(cookies))
+	for k := range cookies {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		c := cookies[name]
+		add := func(id string, sev Severity, title string) {
+			findings = append(findings, Finding{ID: id, Category: "Cookie", Severity: sev, Title: title, Header: "Set-Cookie", Cookie: name, Value: c.String()})
+		}
+
+		session := a.isSession(name)
+
+		if https && !c.Secure {
+			sev := Low
+			if session {
+				sev = Medium
+			}
+			add("cookie-no-secure", sev, "Cookie is missing Secure flag")
+		}
+
+		if !c.HttpOnly {
+			sev := Info
+			if session {
+				sev = Medium
+			}
+			add("cookie-no-httponly", sev, "Cookie is missing HttpOnly flag")
+		}
+
+		switch c.SameSite {
+		case http.SameSiteNoneMode:
+			if !c.Secure {
+				add("cookie-samesite-none-insecure", Medium, "Cookie with SameSite=None is missing Secure flag (rejected by browsers)")
+			} else if session {
+				add("cookie-samesite-none", Low, "Session cookie is sent with cross site requests (SameSite=None)")
+			}
+		case http.SameSiteLaxMode, http.SameSiteStrictMode:
+		default:
+			add("cookie-no-samesite", Info, "Cookie is missing SameSite attribute")
+		}
+
+		// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Set-Cookie#cookie_prefixes
+		if strings.HasPrefix(name, "__Secure-") && !c.Secure {
+			add("cookie-invalid-prefix", Medium, "__Secure- cookie is missing Secure flag")
+		}
+		if strings.HasPrefix(name, "__Host-") && (!c.Secure || c.Domain != "" || c.Path != "/") {
+			add("cookie-invalid-prefix", Medium, "__Host- cookie must be Secure ,have Path=/ and no Domain")
+		}
+	}
+
+	return findings
+}
+
+func (a *Auditor) isSession(name string) bool {
+	name = strings.ToLower(name)
+	for _, s := range a.SessionCookies {
+		if strings.Contains(name, strings.ToLower(s)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHTML : responses without content-type are sniffed as html by browsers
+func isHTML(contenttype string) bool {
+	contenttype = strings.ToLower(contenttype)
+	return contenttype == "" || strings.Contains(contenttype, "html")
+}
@@ -0,0 +1,169 @@
+package audit_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/audit"
+)
+
+func parse(t *testing.T, raw string) *rawhttp.RawHttpResponse {
+	resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte(strings.ReplaceAll(raw, "\n", "\r\n")))
+	if err != nil {
+		t.Fatalf("failed to parse response %v", err)
+	}
+	return resp
+}
+
+func Test_Audit_Weak(t *testing.T) {
+	resp := parse(t, `HTTP/1.1 200 OK
+Content-Type: text/html
+Strict-Transport-Security: max-age=3600; preload
+Content-Security-Policy: default-src 'self'; script-src 'self' 'unsafe-inline' 'unsafe-eval' https:
+X-Frame-Options: ALLOW-FROM https://example.com
+Referrer-Policy: unsafe-url
+Access-Control-Allow-Origin: null
+Set-Cookie: PHPSESSID=abc; Path=/
+Set-Cookie: __Host-id=1; Path=/app; Secure; HttpOnly; SameSite=Lax
+Set-Cookie: pref=dark; SameSite=None
+
+`)
+
+	rep := audit.NewAuditor().Audit(resp)
+
+	expected := []string{
+		"hsts-short-max-age", "hsts-no-subdomains", "hsts-preload-invalid",
+		"nosniff-missing",
+		"csp-unsafe-inline", "csp-unsafe-eval", "csp-wildcard-source", "csp-missing-directive",
+		"framing-invalid", "referrer-policy-unsafe", "permissions-policy-missing",
+		"cors-null-origin",
+		"cookie-no-secure", "cookie-no-httponly", "cookie-no-samesite", "cookie-invalid-prefix", "cookie-samesite-none-insecure",
+	}
+	for _, id := range expected {
+		if !rep.Has(id) {
+			t.Errorf("expected finding %v", id)
+		}
+	}
+
+	for _, f := range rep.Findings {
+		if f.ID == "cookie-no-secure" && f.Cookie == "PHPSESSID" && f.Severity != audit.Medium {
+			t.Errorf("session cookie without secure flag should be medium got %v", audit.SeverityString(f.Severity))
+		}
+		if f.ID == "csp-missing-directive" && f.Value == "object-src" {
+			t.Errorf("object-src falls back to default-src")
+		}
+	}
+
+	// sorted by severity
+	for i := 1; i < len(rep.Findings); i++ {
+		if rep.Findings[i-1].Severity < rep.Findings[i].Severity {
+			t.Fatalf("findings are not sorted")
+		}
+	}
+}
+
+func Test_Audit_Strong(t *testing.T) {
+	resp := parse(t, `HTTP/1.1 200 OK
+Content-Type: text/html; charset=utf-8
+Strict-Transport-Security: max-age=63072000; includeSubDomains; preload
+Content-Security-Policy: default-src 'none'; script-src 'nonce-r4nd0m' 'unsafe-inline' 'strict-dynamic' https:; base-uri 'none'; frame-ancestors 'none'
+X-Content-Type-Options: nosniff
+Referrer-Policy: strict-origin-when-cross-origin
+Permissions-Policy: geolocation=()
+Set-Cookie: __Host-session=abc; Path=/; Secure; HttpOnly; SameSite=Strict
+
+`)
+
+	rep := audit.NewAuditor().Audit(resp)
+	if len(rep.Findings) != 0 {
+		t.Errorf("expected no findings got %+v", rep.Findings)
+	}
+}
+
+func Test_Audit_CORS_Reflected(t *testing.T) {
+	req, err := rawhttp.NewRawHttpRequestFromBytes([]byte("GET /api HTTP/1.1\r\nHost: example.com\r\n\r\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Headers["origin"] = "https://evil.com"
+	resp := parse(t, `HTTP/1.1 200 OK
+Content-Type: application/json
+Access-Control-Allow-Origin: https://evil.com
+Access-Control-Allow-Credentials: true
+
+{}`)
+
+	rep := audit.NewAuditor().AuditRequest(req, resp)
+	if !rep.Has("cors-reflected-origin-credentials") {
+		t.Errorf("reflected origin not detected %+v", rep.Findings)
+	}
+	if rep.Has("csp-missing") {
+		t.Errorf("csp should not be checked for json responses")
+	}
+	if len(rep.Filter(audit.High)) != 1 {
+		t.Errorf("expected one high severity finding")
+	}
+
+	// export
+	bin, err := rep.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded audit.Report
+	if err := json.Unmarshal(bin, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Target != "https://example.com/api" || decoded.Findings[0].Severity != audit.High {
+		t.Errorf("unexpected decoded report %+v", decoded)
+	}
+	if !strings.Contains(string(bin), `"severity": "high"`) {
+		t.Errorf("severity should be exported by name")
+	}
+
+	csv, err := rep.CSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(string(csv)), "\n"); len(lines) != len(rep.Findings)+1 {
+		t.Errorf("unexpected csv %v", string(csv))
+	}
+}
+
+func Test_ParseCSP(t *testing.T) {
+	csp := audit.ParseCSP("Default-Src 'self'; img-src *; script-src 'self' cdn.example.com")
+	if !csp.Has("default-src") {
+		t.Errorf("directive names should be case-insensitive")
+	}
+	if v, _ := csp.Sources("font-src"); len(v) != 1 || v[0] != "'self'" {
+		t.Errorf("font-src should fall back to default-src got %v", v)
+	}
+	if _, ok := csp.Sources("base-uri"); ok {
+		t.Errorf("base-uri does not fall back to default-src")
+	}
+	if csp.String() != "default-src 'self'; script-src 'self' cdn.example.com; img-src *" {
+		t.Errorf("unexpected policy %v", csp.String())
+	}
+}
+
+func Test_Audit_HTTPRequest(t *testing.T) {
+	raw := `HTTP/1.1 200 OK
+Content-Type: text/plain
+Set-Cookie: PHPSESSID=abc; Path=/; HttpOnly; SameSite=Lax
+
+`
+	req := &rawhttp.RawHttpRequest{Scheme: "http", Host: "example.com", Path: "/"}
+
+	// plain http => hsts and secure flag are not applicable
+	rep := audit.NewAuditor().AuditRequest(req, parse(t, raw))
+	if rep.Has("hsts-missing") || rep.Has("cookie-no-secure") {
+		t.Errorf("https findings reported for http request %+v", rep.Findings)
+	}
+
+	req.Scheme = ""
+	rep = audit.NewAuditor().AuditRequest(req, parse(t, raw))
+	if !rep.Has("hsts-missing") || !rep.Has("cookie-no-secure") {
+		t.Errorf("expected https findings for default scheme %+v", rep.Findings)
+	}
+}
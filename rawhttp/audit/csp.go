@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// CSP : Parsed Content-Security-Policy (directive => sources)
+type CSP map[string][]string
+
+// ParseCSP : Parse Content-Security-Policy header value
+// Directive names are lowercased . If multiple policies are present
+// (comma separated) first occurrence of a directive is used
+func ParseCSP(value string) CSP {
+	csp := CSP{}
+	for _, policy := range strings.Split(value, ",") {
+		for _, d := range strings.Split(policy, ";") {
+			fields := strings.Fields(d)
+			if len(fields) == 0 {
+				continue
+			}
+			name := strings.ToLower(fields[0])
+			if _, ok := csp[name]; ok {
+				continue
+			}
+			csp[name] = fields[1:]
+		}
+	}
+	return csp
+}
+
+// Has : Check if directive is present
+func (c CSP) Has(directive string) bool {
+	_, ok := c[strings.ToLower(directive)]
+	return ok
+}
+
+// Sources : Effective sources of fetch directive (falls back to default-src)
+func (c CSP) Sources(directive string) ([]string, bool) {
+	directive = strings.ToLower(directive)
+	if v, ok := c[directive]; ok {
+		return v, true
+	}
+	if strings.HasSuffix(directive, "-src") {
+		if v, ok := c["default-src"]; ok {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// String : Serialize policy (default-src and script-src are written first)
+func (c CSP) String() string {
+	parts := []string{}
+	for _, k := range sortedDirectives(c) {
+		parts = append(parts, strings.TrimSpace(k+" "+strings.Join(c[k], " ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (a *Auditor) auditCSP(headers http.Header) []Finding {
+	value := strings.Join(headers.Values("Content-Security-Policy"), ",")
+	if value == "" {
+		if ro := headers.Get("Content-Security-Policy-Report-Only"); ro != "" {
+			return []Finding{{ID: "csp-report-only", Category: "CSP", Severity: Low, Title: "Content-Security-Policy is only in report-only mode", Header: "Content-Security-Policy-Report-Only", Value: ro}}
+		}
+		return []Finding{{ID: "csp-missing", Category: "CSP", Severity: Medium, Title: "Content-Security-Policy header is missing", Header: "Content-Security-Policy"}}
+	}
+
+	csp := ParseCSP(value)
+	findings := []Finding{}
+	add := func(id string, sev Severity, title string, v string) {
+		findings = append(findings, Finding{ID: id, Category: "CSP", Severity: sev, Title: title, Header: "Content-Security-Policy", Value: v})
+	}
+
+	if scripts, ok := csp.Sources("script-src"); !ok {
+		add("csp-no-script-src", Medium, "CSP does not restrict scripts (script-src and default-src are missing)", value)
+	} else {
+		nonce, strictdynamic := false, false
+		for _, s := range scripts {
+			s = strings.ToLower(s)
+			if strings.HasPrefix(s, "'nonce-") || strings.HasPrefix(s, "'sha256-") || strings.HasPrefix(s, "'sha384-") || strings.HasPrefix(s, "'sha512-") {
+				nonce = true
+			}
+			if s == "'strict-dynamic'" {
+				strictdynamic = true
+			}
+		}
+		for _, s := range scripts {
+			switch strings.ToLower(s) {
+			case "'unsafe-inline'":
+				// ignored by browsers if nonce or hash is present
+				if !nonce {
+					add("csp-unsafe-inline", Medium, "CSP allows inline scripts ('unsafe-inline')", s)
+				}
+			case "'unsafe-eval'":
+				add("csp-unsafe-eval", Low, "CSP allows eval() ('unsafe-eval')", s)
+			case "*", "http:", "https:", "data:", "blob:":
+				// host allowlist is ignored with strict-dynamic
+				if !strictdynamic {
+					add("csp-wildcard-source", Medium, "CSP allows scripts from any source", s)
+				}
+			}
+		}
+	}
+
+	if objects, ok := csp.Sources("object-src"); ok {
+		for _, s := range objects {
+			if s == "*" || strings.EqualFold(s, "data:") || strings.EqualFold(s, "http:") || strings.EqualFold(s, "https:") {
+				add("csp-wildcard-object", Medium, "CSP allows plugins (object-src) from any source", s)
+			}
+		}
+	}
+
+	for _, d := range a.CSPDirectives {
+		if _, ok := csp.Sources(d); ok {
+			continue
+		}
+		sev := Low
+		if strings.EqualFold(d, "frame-ancestors") && headers.Get("X-Frame-Options") != "" {
+			sev = Info
+		}
+		add("csp-missing-directive", sev, "CSP is missing "+d+" directive", d)
+	}
+
+	return findings
+}
+
+// sortedDirectives : fetch directives first followed by others (alphabetical)
+func sortedDirectives(c CSP) []string {
+	first := []string{"default-src", "script-src"}
+	keys := []string{}
+	for _, k := range first {
+		if c.Has(k) {
+			keys = append(keys, k)
+		}
+	}
+	rest := []string{}
+	for k := range c {
+		if k != first[0] && k != first[1] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
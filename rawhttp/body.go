@@ -0,0 +1,784 @@
+package rawhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+Structured model of a raw request body
+
+Body of RawHttpRequest is a plain string . For fuzzing individual
+parameters inside body it is parsed depending on Content-Type
+into one of
+
+1. Form (application/x-www-form-urlencoded) => url.Values
+2. JSON => tree of map[string]interface{} / []interface{}
+3. XML => tree of XMLNode
+4. Multipart (multipart/form-data) => list of MultipartPart
+
+Fields can be accessed using paths
+Form      => `key`
+JSON      => `user.address.0.city` (dot separated , array index as number)
+XML       => `root/user/name` and `root/user/@id` for attributes
+Multipart => `name` (content of part) and `name#filename` (filename of part)
+
+After modifying fields use SetBody() to serialize body back into request.
+Content-Length is not stored in RawHttpRequest (it is a ForbiddenHeader)
+and is always calculated from Body when GetRequest() is called
+*/
+
+// BodyType : Type of request body
+type BodyType int
+
+const (
+	UnknownBody   BodyType = iota
+	FormBody               // application/x-www-form-urlencoded
+	JSONBody               // application/json and other json types
+	XMLBody                // application/xml , text/xml etc
+	MultipartBody          // multipart/form-data
+)
+
+// RequestBody : Parsed Request Body
+type RequestBody struct {
+	Type      BodyType
+	Form      url.Values       // Form Body
+	JSON      interface{}      // JSON Body (numbers are stored as json.Number)
+	XML       *XMLNode         // Root Element of XML Body
+	XMLProlog string           // Anything before root element (ex: <?xml version="1.0"?>)
+	Parts     []*MultipartPart // Parts of multipart body
+	Boundary  string           // Boundary of multipart body
+	Raw       string           // Raw body (used when type is unknown)
+}
+
+// XMLNode : Element of a XML Tree
+type XMLNode struct {
+	Name     xml.Name
+	Attrs    []xml.Attr
+	Text     string // Character data of element
+	Children []*XMLNode
+}
+
+// MultipartPart : Single part of a multipart/form-data body
+type MultipartPart struct {
+	Name        string               // Form field name
+	FileName    string               // filename (if part is a file)
+	ContentType string               // Content-Type of part
+	Header      textproto.MIMEHeader // Other Headers of part
+	Content     string               // Content of part
+}
+
+// BodyTypeOf : Detect body type using content type and body
+func BodyTypeOf(contenttype string, body string) BodyType {
+	mediatype, _, err := mime.ParseMediaType(contenttype)
+	if err != nil {
+		mediatype = strings.ToLower(contenttype)
+	}
+
+	switch {
+	case mediatype == "application/x-www-form-urlencoded":
+		return FormBody
+	case mediatype == "multipart/form-data":
+		return MultipartBody
+	case strings.Contains(mediatype, "json"):
+		return JSONBody
+	case strings.Contains(mediatype, "xml"):
+		return XMLBody
+	}
+
+	// Content-Type is missing or misleading try to guess
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return UnknownBody
+	}
+	if (strings.HasPrefix(body, "{") || strings.HasPrefix(body, "[")) && json.Valid([]byte(body)) {
+		return JSONBody
+	}
+	if strings.HasPrefix(body, "<") {
+		return XMLBody
+	}
+	if strings.Contains(body, "=") && !strings.ContainsAny(body, " \n") {
+		return FormBody
+	}
+
+	return UnknownBody
+}
+
+// ParseBody : Parse request body depending on its Content-Type
+func (r *RawHttpRequest) ParseBody() (*RequestBody, error) {
+	return ParseRequestBody(r.ContentType, r.Body)
+}
+
+// SetBody : Serialize given body and update Body & Content-Type of request
+// If multipart body has no boundary a random boundary is used (given body is not modified)
+func (r *RawHttpRequest) SetBody(b *RequestBody) error {
+	if b.Type == MultipartBody && b.Boundary == "" {
+		withboundary := *b
+		withboundary.Boundary = multipart.NewWriter(nil).Boundary()
+		b = &withboundary
+	}
+
+	data, err := b.Encode()
+	if err != nil {
+		return err
+	}
+
+	r.Body = data
+	r.HasBody = len(data) > 0
+
+	if b.Type == MultipartBody {
+		r.ContentType = mime.FormatMediaType("multipart/form-data", map[string]string{"boundary": b.Boundary})
+		if _, ok := r.Headers["content-type"]; ok {
+			r.Headers["content-type"] = r.ContentType
+		}
+	}
+
+	return nil
+}
+
+// ParseRequestBody : Parse given body using content type
+func ParseRequestBody(contenttype string, body string) (*RequestBody, error) {
+	b := &RequestBody{
+		Type: BodyTypeOf(contenttype, body),
+		Raw:  body,
+	}
+
+	switch b.Type {
+	case FormBody:
+		vals, err := url.ParseQuery(body)
+		if err != nil {
+			return b, fmt.Errorf("failed to parse form body %v", err)
+		}
+		b.Form = vals
+
+	case JSONBody:
+		dec := json.NewDecoder(strings.NewReader(body))
+		dec.UseNumber()
+		if err := dec.Decode(&b.JSON); err != nil {
+			return b, fmt.Errorf("failed to parse json body %v", err)
+		}
+
+	case XMLBody:
+		prolog, root, err := parseXML(body)
+		if err != nil {
+			return b, fmt.Errorf("failed to parse xml body %v", err)
+		}
+		b.XMLProlog = prolog
+		b.XML = root
+
+	case MultipartBody:
+		_, params, err := mime.ParseMediaType(contenttype)
+		if err != nil || params["boundary"] == "" {
+			return b, fmt.Errorf("multipart boundary missing in %v", contenttype)
+		}
+		b.Boundary = params["boundary"]
+		parts, err := parseMultipart(body, b.Boundary)
+		if err != nil {
+			return b, fmt.Errorf("failed to parse multipart body %v", err)
+		}
+		b.Parts = parts
+	}
+
+	return b, nil
+}
+
+// Encode : Serialize body to string (multipart body must have Boundary)
+func (b *RequestBody) Encode() (string, error) {
+	switch b.Type {
+	case FormBody:
+		return b.Form.Encode(), nil
+
+	case JSONBody:
+		var buff bytes.Buffer
+		enc := json.NewEncoder(&buff)
+		// payloads must not be escaped
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(b.JSON); err != nil {
+			return "", err
+		}
+		return strings.TrimSuffix(buff.String(), "\n"), nil
+
+	case XMLBody:
+		if b.XML == nil {
+			return b.XMLProlog, nil
+		}
+		var sb strings.Builder
+		sb.WriteString(b.XMLProlog)
+		writeXML(&sb, b.XML)
+		return sb.String(), nil
+
+	case MultipartBody:
+		// boundary must be known to build Content-Type (SetBody generates it)
+		if b.Boundary == "" {
+			return "", fmt.Errorf("multipart boundary missing")
+		}
+		return encodeMultipart(b.Parts, b.Boundary)
+	}
+
+	return b.Raw, nil
+}
+
+// Paths : All field paths available in body (sorted for form and json)
+func (b *RequestBody) Paths() []string {
+	paths := []string{}
+
+	switch b.Type {
+	case FormBody:
+		for k := range b.Form {
+			paths = append(paths, k)
+		}
+		sort.Strings(paths)
+
+	case JSONBody:
+		jsonPaths(b.JSON, "", &paths)
+		sort.Strings(paths)
+
+	case XMLBody:
+		if b.XML != nil {
+			xmlPaths(b.XML, "", &paths)
+		}
+
+	case MultipartBody:
+		for _, p := range b.Parts {
+			paths = append(paths, p.Name)
+			if p.FileName != "" {
+				paths = append(paths, p.Name+"#filename")
+			}
+		}
+	}
+
+	return paths
+}
+
+// Get : Get value of field at given path
+func (b *RequestBody) Get(path string) (string, bool) {
+	switch b.Type {
+	case FormBody:
+		if _, ok := b.Form[path]; !ok {
+			return "", false
+		}
+		return b.Form.Get(path), true
+
+	case JSONBody:
+		val, ok := jsonGet(b.JSON, splitJSONPath(path))
+		if !ok {
+			return "", false
+		}
+		return jsonString(val), true
+
+	case XMLBody:
+		node, attr := b.xmlLookup(path)
+		if node == nil {
+			return "", false
+		}
+		if attr != "" {
+			for _, a := range node.Attrs {
+				if a.Name.Local == attr {
+					return a.Value, true
+				}
+			}
+			return "", false
+		}
+		return node.Text, true
+
+	case MultipartBody:
+		name, wantfile := splitPartPath(path)
+		for _, p := range b.Parts {
+			if p.Name == name {
+				if wantfile {
+					return p.FileName, true
+				}
+				return p.Content, true
+			}
+		}
+	}
+
+	return "", false
+}
+
+// Set : Set value of field at given path (value is always stored as string)
+func (b *RequestBody) Set(path string, value string) error {
+	switch b.Type {
+	case FormBody:
+		if b.Form == nil {
+			b.Form = url.Values{}
+		}
+		b.Form.Set(path, value)
+		return nil
+
+	case JSONBody:
+		updated, err := jsonSet(b.JSON, splitJSONPath(path), value)
+		if err != nil {
+			return err
+		}
+		b.JSON = updated
+		return nil
+
+	case XMLBody:
+		node, attr := b.xmlLookup(path)
+		if node == nil {
+			return fmt.Errorf("xml element %v not found", path)
+		}
+		if attr != "" {
+			for i, a := range node.Attrs {
+				if a.Name.Local == attr {
+					node.Attrs[i].Value = value
+					return nil
+				}
+			}
+			node.Attrs = append(node.Attrs, xml.Attr{Name: xml.Name{Local: attr}, Value: value})
+			return nil
+		}
+		node.Text = value
+		return nil
+
+	case MultipartBody:
+		name, wantfile := splitPartPath(path)
+		for _, p := range b.Parts {
+			if p.Name == name {
+				if wantfile {
+					p.FileName = value
+				} else {
+					p.Content = value
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("multipart part %v not found", name)
+	}
+
+	return fmt.Errorf("body type does not support fields")
+}
+
+// Clone : Deep copy of body
+func (b *RequestBody) Clone() *RequestBody {
+	c := *b
+
+	if b.Form != nil {
+		c.Form = url.Values{}
+		for k, v := range b.Form {
+			c.Form[k] = append([]string{}, v...)
+		}
+	}
+
+	c.JSON = jsonClone(b.JSON)
+
+	if b.XML != nil {
+		c.XML = b.XML.clone()
+	}
+
+	if b.Parts != nil {
+		c.Parts = make([]*MultipartPart, len(b.Parts))
+		for i, p := range b.Parts {
+			np := *p
+			np.Header = textproto.MIMEHeader{}
+			for k, v := range p.Header {
+				np.Header[k] = append([]string{}, v...)
+			}
+			c.Parts[i] = &np
+		}
+	}
+
+	return &c
+}
+
+/* JSON helpers */
+
+func splitJSONPath(path string) []string {
+	if path == "" {
+		return []string{}
+	}
+	return strings.Split(path, ".")
+}
+
+func jsonPaths(v interface{}, prefix string, paths *[]string) {
+	join := func(k string) string {
+		if prefix == "" {
+			return k
+		}
+		return prefix + "." + k
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			jsonPaths(val, join(k), paths)
+		}
+	case []interface{}:
+		for i, val := range t {
+			jsonPaths(val, join(strconv.Itoa(i)), paths)
+		}
+	default:
+		if prefix != "" {
+			*paths = append(*paths, prefix)
+		}
+	}
+}
+
+func jsonGet(v interface{}, path []string) (interface{}, bool) {
+	if len(path) == 0 {
+		return v, true
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		val, ok := t[path[0]]
+		if !ok {
+			return nil, false
+		}
+		return jsonGet(val, path[1:])
+	case []interface{}:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i >= len(t) {
+			return nil, false
+		}
+		return jsonGet(t[i], path[1:])
+	}
+
+	return nil, false
+}
+
+func jsonSet(v interface{}, path []string, value string) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		updated, err := jsonSet(t[path[0]], path[1:], value)
+		if err != nil {
+			return v, err
+		}
+		t[path[0]] = updated
+		return t, nil
+	case []interface{}:
+		i, err := strconv.Atoi(path[0])
+		if err != nil || i < 0 || i >= len(t) {
+			return v, fmt.Errorf("invalid array index %v", path[0])
+		}
+		updated, err := jsonSet(t[i], path[1:], value)
+		if err != nil {
+			return v, err
+		}
+		t[i] = updated
+		return t, nil
+	case nil:
+		// create missing objects
+		updated, err := jsonSet(map[string]interface{}{}, path, value)
+		return updated, err
+	}
+
+	return v, fmt.Errorf("cannot set %v on a json value", strings.Join(path, "."))
+}
+
+func jsonString(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return t
+	case json.Number:
+		return t.String()
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(t)
+	}
+	bin, _ := json.Marshal(v)
+	return string(bin)
+}
+
+func jsonClone(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, val := range t {
+			m[k] = jsonClone(val)
+		}
+		return m
+	case []interface{}:
+		arr := make([]interface{}, len(t))
+		for i, val := range t {
+			arr[i] = jsonClone(val)
+		}
+		return arr
+	}
+	return v
+}
+
+/* XML helpers */
+
+func parseXML(body string) (string, *XMLNode, error) {
+	dec := xml.NewDecoder(strings.NewReader(body))
+	dec.Strict = false
+
+	var root *XMLNode
+	stack := []*XMLNode{}
+	var prolog strings.Builder
+
+	for {
+		start := dec.InputOffset()
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			node := &XMLNode{Name: t.Name, Attrs: append([]xml.Attr{}, t.Attr...)}
+			if len(stack) == 0 {
+				if root != nil {
+					return "", nil, fmt.Errorf("multiple root elements")
+				}
+				root = node
+			} else {
+				parent := stack[len(stack)-1]
+				parent.Children = append(parent.Children, node)
+			}
+			stack = append(stack, node)
+		case xml.EndElement:
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		case xml.CharData:
+			if len(stack) > 0 {
+				stack[len(stack)-1].Text += string(t)
+			} else if root == nil {
+				prolog.WriteString(body[start:dec.InputOffset()])
+			}
+		default:
+			if root == nil {
+				// processing instructions ,doctype etc before root
+				prolog.WriteString(body[start:dec.InputOffset()])
+			}
+		}
+	}
+
+	if root == nil {
+		return "", nil, fmt.Errorf("root element not found")
+	}
+
+	// whitespace between elements is not part of value
+	root.trim()
+
+	return prolog.String(), root, nil
+}
+
+func (n *XMLNode) trim() {
+	if len(n.Children) > 0 {
+		n.Text = strings.TrimSpace(n.Text)
+	}
+	for _, c := range n.Children {
+		c.trim()
+	}
+}
+
+func (n *XMLNode) clone() *XMLNode {
+	c := &XMLNode{
+		Name:  n.Name,
+		Attrs: append([]xml.Attr{}, n.Attrs...),
+		Text:  n.Text,
+	}
+	for _, child := range n.Children {
+		c.Children = append(c.Children, child.clone())
+	}
+	return c
+}
+
+func xmlName(n xml.Name) string {
+	if n.Space != "" {
+		return n.Space + ":" + n.Local
+	}
+	return n.Local
+}
+
+func writeXML(sb *strings.Builder, n *XMLNode) {
+	sb.WriteString("<" + xmlName(n.Name))
+	for _, a := range n.Attrs {
+		sb.WriteString(" " + xmlName(a.Name) + "=\"")
+		xml.EscapeText(sb, []byte(a.Value))
+		sb.WriteString("\"")
+	}
+	if n.Text == "" && len(n.Children) == 0 {
+		sb.WriteString("/>")
+		return
+	}
+	sb.WriteString(">")
+	xml.EscapeText(sb, []byte(n.Text))
+	for _, c := range n.Children {
+		writeXML(sb, c)
+	}
+	sb.WriteString("</" + xmlName(n.Name) + ">")
+}
+
+// xmlPaths : only leaf elements and attributes are considered as fields
+func xmlPaths(n *XMLNode, prefix string, paths *[]string) {
+	path := xmlName(n.Name)
+	if prefix != "" {
+		path = prefix + "/" + path
+	}
+	for _, a := range n.Attrs {
+		if a.Name.Space == "xmlns" || a.Name.Local == "xmlns" {
+			continue
+		}
+		*paths = append(*paths, path+"/@"+a.Name.Local)
+	}
+	if len(n.Children) == 0 {
+		*paths = append(*paths, path)
+		return
+	}
+	for _, c := range n.Children {
+		xmlPaths(c, path, paths)
+	}
+}
+
+// xmlLookup : returns element and attribute name (if path points to an attribute)
+func (b *RequestBody) xmlLookup(path string) (*XMLNode, string) {
+	if b.XML == nil {
+		return nil, ""
+	}
+
+	elems := strings.Split(strings.Trim(path, "/"), "/")
+	attr := ""
+	if last := elems[len(elems)-1]; strings.HasPrefix(last, "@") {
+		attr = strings.TrimPrefix(last, "@")
+		elems = elems[:len(elems)-1]
+	}
+
+	if len(elems) == 0 || elems[0] != xmlName(b.XML.Name) {
+		return nil, ""
+	}
+
+	node := b.XML
+	for _, name := range elems[1:] {
+		var next *XMLNode
+		for _, c := range node.Children {
+			if xmlName(c.Name) == name {
+				next = c
+				break
+			}
+		}
+		if next == nil {
+			return nil, ""
+		}
+		node = next
+	}
+
+	return node, attr
+}
+
+/* Multipart helpers */
+
+func splitPartPath(path string) (string, bool) {
+	if strings.HasSuffix(path, "#filename") {
+		return strings.TrimSuffix(path, "#filename"), true
+	}
+	return path, false
+}
+
+func parseMultipart(body string, boundary string) ([]*MultipartPart, error) {
+	// body might be trimmed while parsing raw request
+	if !strings.HasSuffix(body, "\n") {
+		if strings.Contains(body, "\r\n") {
+			body += "\r\n"
+		} else {
+			body += "\n"
+		}
+	}
+
+	mr := multipart.NewReader(strings.NewReader(body), boundary)
+	parts := []*MultipartPart{}
+
+	for {
+		p, err := mr.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return parts, err
+		}
+
+		bin, err := io.ReadAll(p)
+		if err != nil {
+			return parts, err
+		}
+
+		part := &MultipartPart{
+			Name:        p.FormName(),
+			FileName:    partFileName(p),
+			ContentType: p.Header.Get("Content-Type"),
+			Header:      textproto.MIMEHeader{},
+			Content:     string(bin),
+		}
+		for k, v := range p.Header {
+			if k == "Content-Disposition" || k == "Content-Type" {
+				continue
+			}
+			part.Header[k] = v
+		}
+		parts = append(parts, part)
+	}
+
+	return parts, nil
+}
+
+// partFileName : multipart.Part.FileName() strips directories which
+// is not desired while fuzzing
+func partFileName(p *multipart.Part) string {
+	_, params, err := mime.ParseMediaType(p.Header.Get("Content-Disposition"))
+	if err != nil {
+		return p.FileName()
+	}
+	return params["filename"]
+}
+
+func encodeMultipart(parts []*MultipartPart, boundary string) (string, error) {
+	var buff bytes.Buffer
+	mw := multipart.NewWriter(&buff)
+	if boundary != "" {
+		if err := mw.SetBoundary(boundary); err != nil {
+			return "", err
+		}
+	}
+
+	for _, p := range parts {
+		h := textproto.MIMEHeader{}
+		for k, v := range p.Header {
+			h[k] = v
+		}
+		// filenames are not escaped on purpose (fuzzing)
+		disposition := fmt.Sprintf(`form-data; name="%s"`, p.Name)
+		if p.FileName != "" {
+			disposition += fmt.Sprintf(`; filename="%s"`, p.FileName)
+		}
+		h.Set("Content-Disposition", disposition)
+		if p.ContentType != "" {
+			h.Set("Content-Type", p.ContentType)
+		}
+
+		w, err := mw.CreatePart(h)
+		if err != nil {
+			return "", err
+		}
+		if _, err := io.WriteString(w, p.Content); err != nil {
+			return "", err
+		}
+	}
+
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+
+	return buff.String(), nil
+}
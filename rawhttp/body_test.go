@@ -0,0 +1,133 @@
+package rawhttp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+func Test_FormBody(t *testing.T) {
+	raw := "POST /login HTTP/1.1\nHost: example.com\nContent-Type: application/x-www-form-urlencoded\n\nuser=admin&pass=secret"
+
+	req, err := rawhttp.NewRawHttpRequest(raw)
+	if err != nil {
+		t.Fatalf("failed to parse request %v", err)
+	}
+
+	body, err := req.ParseBody()
+	if err != nil || body.Type != rawhttp.FormBody {
+		t.Fatalf("expected form body got %v %v", body.Type, err)
+	}
+
+	body.Set("user", "' or 1=1--")
+	if err := req.SetBody(body); err != nil {
+		t.Fatalf("failed to set body %v", err)
+	}
+
+	if req.Body != "pass=secret&user=%27+or+1%3D1--" {
+		t.Errorf("unexpected form body %v", req.Body)
+	}
+
+	if r := req.GetRequest(); r.ContentLength != int64(len(req.Body)) {
+		t.Errorf("content length mismatch %v", r.ContentLength)
+	}
+}
+
+func Test_JSONBody(t *testing.T) {
+	body, err := rawhttp.ParseRequestBody("application/json", `{"user":{"name":"bob","ids":[1,2]},"active":true}`)
+	if err != nil {
+		t.Fatalf("failed to parse json %v", err)
+	}
+
+	paths := strings.Join(body.Paths(), ",")
+	if paths != "active,user.ids.0,user.ids.1,user.name" {
+		t.Errorf("unexpected paths %v", paths)
+	}
+
+	if v, _ := body.Get("user.ids.1"); v != "2" {
+		t.Errorf("expected 2 got %v", v)
+	}
+
+	if err := body.Set("user.name", "<script>"); err != nil {
+		t.Fatalf("failed to set json field %v", err)
+	}
+
+	out, _ := body.Encode()
+	if out != `{"active":true,"user":{"ids":[1,2],"name":"<script>"}}` {
+		t.Errorf("unexpected json %v", out)
+	}
+}
+
+func Test_XMLBody(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<user id="1">
+	<name>bob</name>
+</user>`
+
+	body, err := rawhttp.ParseRequestBody("text/xml", data)
+	if err != nil {
+		t.Fatalf("failed to parse xml %v", err)
+	}
+
+	paths := strings.Join(body.Paths(), ",")
+	if paths != "user/@id,user/name" {
+		t.Errorf("unexpected paths %v", paths)
+	}
+
+	body.Set("user/@id", "2")
+	body.Set("user/name", "a&b")
+
+	out, _ := body.Encode()
+	if out != "<?xml version=\"1.0\"?>\n<user id=\"2\"><name>a&amp;b</name></user>" {
+		t.Errorf("unexpected xml %v", out)
+	}
+}
+
+func Test_MultipartBody(t *testing.T) {
+	raw := "POST /upload HTTP/1.1\nHost: example.com\nContent-Type: multipart/form-data; boundary=xyz\n\n" +
+		"--xyz\nContent-Disposition: form-data; name=\"title\"\n\nhello\n" +
+		"--xyz\nContent-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\nContent-Type: text/plain\n\nfile content\n" +
+		"--xyz--"
+
+	req, err := rawhttp.NewRawHttpRequest(raw)
+	if err != nil {
+		t.Fatalf("failed to parse request %v", err)
+	}
+
+	body, err := req.ParseBody()
+	if err != nil {
+		t.Fatalf("failed to parse multipart %v", err)
+	}
+
+	if len(body.Parts) != 2 || body.Parts[1].FileName != "a.txt" {
+		t.Fatalf("unexpected parts %v", body.Parts)
+	}
+
+	body.Set("file#filename", "../../shell.php")
+	req.SetBody(body)
+
+	again, err := req.ParseBody()
+	if err != nil {
+		t.Fatalf("failed to parse serialized multipart %v", err)
+	}
+	if v, _ := again.Get("file#filename"); v != "../../shell.php" {
+		t.Errorf("filename not updated got %v", v)
+	}
+	if v, _ := again.Get("title"); v != "hello" {
+		t.Errorf("content changed got %v", v)
+	}
+
+	// boundary is generated by SetBody without modifying body
+	body.Boundary = ""
+	if _, err := body.Encode(); err == nil || body.Boundary != "" {
+		t.Errorf("expected error without boundary got %q %v", body.Boundary, err)
+	}
+	if err := req.SetBody(body); err != nil || body.Boundary != "" {
+		t.Errorf("setbody modified boundary got %q %v", body.Boundary, err)
+	}
+	again, err = req.ParseBody()
+	if err != nil || len(again.Parts) != 2 {
+		t.Errorf("generated boundary does not match content type %v %v", req.ContentType, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strconv"
 	"time"
@@ -250,6 +251,28 @@ func (c *SHTTPClient) Do(req *http.Request) (*http.Response, error) {
 
 }
 
+// DoRaw : Send HTTP Request and parse response
+// Duration of response is time from connection request till body is read
+// (time spent waiting for rate limit or before retries is not included)
+func (c *SHTTPClient) DoRaw(req *http.Request) (*RawHttpResponse, error) {
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			// called again on every retry
+			start = time.Now()
+		},
+	}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := NewRawHttpResponse(resp)
+	raw.Duration = time.Since(start)
+	return raw, err
+}
+
 func (c *SHTTPClient) timeoutretry(req *http.Request, retrycount int) (*http.Response, error) {
 
 	resp, err := c.client.Do(req)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -113,3 +114,23 @@ func Test_UnavailableServer(t *testing.T) {
 	}
 
 }
+
+func Test_DoRaw(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		fmt.Fprintf(w, "slow")
+	}))
+	defer ts.Close()
+
+	c := rawhttp.SHTTPClient{}
+	c.Create()
+
+	req, _ := http.NewRequest("GET", ts.URL, nil)
+	resp, err := c.DoRaw(req)
+	if err != nil {
+		t.Fatalf("request failed %v", err)
+	}
+	if string(resp.Body) != "slow" || resp.Duration < 100*time.Millisecond || resp.Duration > 5*time.Second {
+		t.Errorf("unexpected response %v %v", string(resp.Body), resp.Duration)
+	}
+}
@@ -0,0 +1,406 @@
+package rawhttp
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+/*
+Import & Export curl commands
+
+Supported curl options
+-X/--request , -H/--header , -d/--data/--data-raw/--data-binary/--data-ascii ,
+--data-urlencode , -b/--cookie , -u/--user , -A/--user-agent , -e/--referer ,
+-G/--get , -I/--head , --url , --compressed
+
+Client related options like -k ,-L ,-s ,--proxy etc are ignored since
+they are not part of request (use SHTTPClient settings instead)
+Multiline commands (\ at end of line) and $'...' quoting (chrome/firefox copy as curl)
+are supported
+
+Scheme of url is kept in RawHttpRequest.Scheme (https if url has no scheme)
+*/
+
+// curl options that take an argument but do not change request
+var curlIgnoredArgs = map[string]bool{
+	"-o": true, "--output": true, "-x": true, "--proxy": true, "-m": true, "--max-time": true,
+	"--connect-timeout": true, "--retry": true, "-w": true, "--write-out": true, "--cacert": true,
+	"--cert": true, "--key": true, "-U": true, "--proxy-user": true, "--resolve": true,
+	"-c": true, "--cookie-jar": true, "--max-redirs": true, "-r": true, "--range": true,
+}
+
+// NewRawHttpRequestFromCurl : New Raw Http Request From curl command
+func NewRawHttpRequestFromCurl(cmd string) (*RawHttpRequest, error) {
+	args, err := shellSplit(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 0 || args[0] != "curl" {
+		return nil, fmt.Errorf("not a curl command")
+	}
+
+	var (
+		verb     string
+		rawurl   string
+		headers  [][2]string
+		data     []string
+		cookies  []string
+		get      bool
+		head     bool
+		hasctype bool
+	)
+
+	addHeader := func(k, v string) {
+		if strings.EqualFold(k, "content-type") {
+			hasctype = true
+		}
+		headers = append(headers, [2]string{k, v})
+	}
+
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		// options with value (ex: -XPOST or --request=POST)
+		name, value, hasvalue := arg, "", false
+		if strings.HasPrefix(arg, "--") {
+			if j := strings.Index(arg, "="); j > 0 {
+				name, value, hasvalue = arg[:j], arg[j+1:], true
+			}
+		} else if strings.HasPrefix(arg, "-") && len(arg) > 2 {
+			name, value, hasvalue = arg[:2], arg[2:], true
+		}
+
+		next := func() (string, error) {
+			if hasvalue {
+				return value, nil
+			}
+			if i+1 >= len(args) {
+				return "", fmt.Errorf("missing value for curl option %v", name)
+			}
+			i++
+			return args[i], nil
+		}
+
+		switch name {
+		case "-X", "--request":
+			verb, err = next()
+		case "-H", "--header":
+			var h string
+			h, err = next()
+			if kv := strings.SplitN(h, ":", 2); len(kv) == 2 {
+				addHeader(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+			}
+		case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw":
+			var d string
+			d, err = next()
+			if name != "--data-raw" && strings.HasPrefix(d, "@") {
+				return nil, fmt.Errorf("reading data from file %v is not supported", d)
+			}
+			data = append(data, d)
+		case "--data-urlencode":
+			var d string
+			d, err = next()
+			if kv := strings.SplitN(d, "=", 2); len(kv) == 2 {
+				data = append(data, kv[0]+"="+url.QueryEscape(kv[1]))
+			} else {
+				data = append(data, url.QueryEscape(d))
+			}
+		case "-b", "--cookie":
+			var c string
+			c, err = next()
+			cookies = append(cookies, c)
+		case "-u", "--user":
+			var u string
+			u, err = next()
+			addHeader("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(u)))
+		case "-A", "--user-agent":
+			var ua string
+			ua, err = next()
+			addHeader("User-Agent", ua)
+		case "-e", "--referer":
+			var ref string
+			ref, err = next()
+			addHeader("Referer", ref)
+		case "--url":
+			rawurl, err = next()
+		case "-G", "--get":
+			get = true
+		case "-I", "--head":
+			head = true
+		case "--compressed":
+			addHeader("Accept-Encoding", "deflate, gzip")
+		default:
+			if curlIgnoredArgs[name] {
+				_, err = next()
+			} else if !strings.HasPrefix(arg, "-") {
+				rawurl = arg
+			}
+			// other flags (-k ,-L ,-s ,-v etc) are ignored
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rawurl == "" {
+		return nil, fmt.Errorf("url missing in curl command")
+	}
+	if !strings.Contains(rawurl, "://") {
+		rawurl = "https://" + rawurl
+	}
+
+	u, err := url.Parse(rawurl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse url %v", err)
+	}
+
+	body := strings.Join(data, "&")
+
+	if get && body != "" {
+		// -G sends data as query parameters
+		if u.RawQuery != "" {
+			u.RawQuery += "&"
+		}
+		u.RawQuery += body
+		body = ""
+	}
+
+	if verb == "" {
+		switch {
+		case head:
+			verb = "HEAD"
+		case body != "":
+			verb = "POST"
+		default:
+			verb = "GET"
+		}
+	}
+
+	if body != "" && !hasctype {
+		addHeader("Content-Type", "application/x-www-form-urlencoded")
+	}
+
+	// fields are set directly (raw request parser trims body and splits header values at ':')
+	r := &RawHttpRequest{
+		Verb:    verb,
+		Path:    u.RequestURI(),
+		Host:    u.Host,
+		Params:  u.Query(),
+		Headers: map[string]string{},
+		Cookies: map[string]string{},
+		Body:    body,
+		HasBody: body != "",
+		Scheme:  u.Scheme,
+	}
+
+	for _, h := range headers {
+		key := strings.ToLower(h[0])
+		switch {
+		case key == "host":
+			r.Host = h[1]
+		case ForbiddenHeaders[key]:
+		case key == "cookie":
+			cookies = append(cookies, h[1])
+		default:
+			r.Headers[key] = h[1]
+			if key == "content-type" {
+				r.ContentType = h[1]
+			}
+		}
+	}
+	if r.Host != u.Host {
+		// Host header was overridden
+		r.PredefinedHost = u.Host
+	}
+
+	for _, c := range cookies {
+		for _, pair := range strings.Split(c, ";") {
+			if k, v, ok := strings.Cut(pair, "="); ok {
+				r.Cookies[strings.TrimSpace(k)] = strings.TrimSpace(v)
+			}
+		}
+	}
+
+	r.RawURL = r.baseURL() + r.Path
+
+	return r, nil
+}
+
+// Curl : Generate shell safe curl command from raw request
+func (r *RawHttpRequest) Curl() string {
+	req := r.GetRequest()
+	if req == nil {
+		// invalid url (see BuildRequest)
+		return ""
+	}
+
+	args := []string{"curl"}
+
+	switch {
+	case r.Verb == "GET" && !r.HasBody, r.Verb == "POST" && r.HasBody:
+	case r.Verb == "HEAD" && !r.HasBody:
+		// -X HEAD waits for a body that is never sent
+		args = append(args, "-I")
+	default:
+		args = append(args, "-X", shellQuote(r.Verb))
+	}
+
+	args = append(args, shellQuote(req.URL.String()))
+
+	if r.PredefinedHost != "" && r.PredefinedHost != r.Host {
+		args = append(args, "-H", shellQuote("Host: "+r.Host))
+	}
+
+	for _, k := range sortedKeys(req.Header) {
+		if k == "Cookie" {
+			continue
+		}
+		for _, v := range req.Header[k] {
+			args = append(args, "-H", shellQuote(k+": "+v))
+		}
+	}
+
+	if len(r.Cookies) > 0 {
+		cookies := []string{}
+		for _, k := range sortedKeys(r.Cookies) {
+			cookies = append(cookies, k+"="+r.Cookies[k])
+		}
+		args = append(args, "-b", shellQuote(strings.Join(cookies, "; ")))
+	}
+
+	if r.HasBody {
+		args = append(args, "--data-raw", shellQuote(r.Body))
+	}
+
+	return strings.Join(args, " ")
+}
+
+// shellQuote : Quote string using single quotes (safe for sh/bash/zsh)
+func shellQuote(s string) string {
+	if s != "" && strings.IndexFunc(s, func(c rune) bool {
+		return !(('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || strings.ContainsRune("-_./:=@", c))
+	}) < 0 {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// shellSplit : Split command line into arguments (similar to posix shell)
+func shellSplit(cmd string) ([]string, error) {
+	args := []string{}
+	var sb strings.Builder
+	inarg := false
+
+	rs := []rune(cmd)
+	for i := 0; i < len(rs); i++ {
+		c := rs[i]
+
+		switch {
+		case c == '\\':
+			if i+1 < len(rs) {
+				i++
+				if rs[i] == '\n' || (rs[i] == '\r' && i+1 < len(rs) && rs[i+1] == '\n') {
+					// line continuation
+					if rs[i] == '\r' {
+						i++
+					}
+					continue
+				}
+				sb.WriteRune(rs[i])
+				inarg = true
+			}
+
+		case c == '\'':
+			end := indexRune(rs, i+1, '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated single quote in command")
+			}
+			sb.WriteString(string(rs[i+1 : end]))
+			i = end
+			inarg = true
+
+		case c == '$' && i+1 < len(rs) && rs[i+1] == '\'':
+			// ansi-c quoting
+			j := i + 2
+			for ; j < len(rs) && rs[j] != '\''; j++ {
+				if rs[j] == '\\' && j+1 < len(rs) {
+					j++
+					switch rs[j] {
+					case 'n':
+						sb.WriteRune('\n')
+					case 'r':
+						sb.WriteRune('\r')
+					case 't':
+						sb.WriteRune('\t')
+					case 'x':
+						if j+2 < len(rs) {
+							var b byte
+							if _, err := fmt.Sscanf(string(rs[j+1:j+3]), "%02x", &b); err == nil {
+								sb.WriteByte(b)
+								j += 2
+								continue
+							}
+						}
+						sb.WriteRune('x')
+					default:
+						sb.WriteRune(rs[j])
+					}
+					continue
+				}
+				sb.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("unterminated ansi-c quote in command")
+			}
+			i = j
+			inarg = true
+
+		case c == '"':
+			j := i + 1
+			for ; j < len(rs) && rs[j] != '"'; j++ {
+				if rs[j] == '\\' && j+1 < len(rs) && strings.ContainsRune("\"\\$`\n", rs[j+1]) {
+					j++
+					if rs[j] == '\n' {
+						continue
+					}
+				}
+				sb.WriteRune(rs[j])
+			}
+			if j >= len(rs) {
+				return nil, fmt.Errorf("unterminated double quote in command")
+			}
+			i = j
+			inarg = true
+
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inarg {
+				args = append(args, sb.String())
+				sb.Reset()
+				inarg = false
+			}
+
+		default:
+			sb.WriteRune(c)
+			inarg = true
+		}
+	}
+
+	if inarg {
+		args = append(args, sb.String())
+	}
+
+	return args, nil
+}
+
+func indexRune(rs []rune, start int, r rune) int {
+	for i := start; i < len(rs); i++ {
+		if rs[i] == r {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,135 @@
+package rawhttp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+func Test_CurlImport(t *testing.T) {
+	cmd := `curl 'https://example.com/api/login?next=%2Fhome' \
+  -X PUT \
+  -H 'Content-Type: application/json' \
+  -H "X-Token: a\"b" \
+  -b 'session=abc; theme=dark' \
+  -u admin:secret \
+  --compressed -k \
+  --data-raw $'{"user":"it\'s me"}'`
+
+	req, err := rawhttp.NewRawHttpRequestFromCurl(cmd)
+	if err != nil {
+		t.Fatalf("failed to parse curl command %v", err)
+	}
+
+	if req.Verb != "PUT" || req.Host != "example.com" || req.Params.Get("next") != "/home" {
+		t.Errorf("unexpected request line %v %v %v", req.Verb, req.Host, req.Params)
+	}
+
+	if req.Headers["x-token"] != `a"b` || req.Headers["authorization"] != "Basic YWRtaW46c2VjcmV0" {
+		t.Errorf("unexpected headers %v", req.Headers)
+	}
+
+	if req.Cookies["session"] != "abc" || req.Cookies["theme"] != "dark" {
+		t.Errorf("unexpected cookies %v", req.Cookies)
+	}
+
+	if req.Body != `{"user":"it's me"}` || req.ContentType != "application/json" {
+		t.Errorf("unexpected body %v", req.Body)
+	}
+}
+
+func Test_CurlDefaults(t *testing.T) {
+	req, err := rawhttp.NewRawHttpRequestFromCurl(`curl example.com/search -d q=test -d page=2`)
+	if err != nil {
+		t.Fatalf("failed to parse curl command %v", err)
+	}
+
+	if req.Verb != "POST" || req.Body != "q=test&page=2" || req.ContentType != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected request %v %v %v", req.Verb, req.Body, req.ContentType)
+	}
+
+	req, _ = rawhttp.NewRawHttpRequestFromCurl(`curl -G example.com/search -d q=test`)
+	if req.Verb != "GET" || req.Params.Get("q") != "test" || req.HasBody {
+		t.Errorf("-G must send data as query %v", req)
+	}
+}
+
+func Test_CurlExport(t *testing.T) {
+	raw := "POST /login HTTP/1.1\nHost: example.com\nX-Test: it's\nCookie: a=1\nContent-Type: application/x-www-form-urlencoded\n\nuser=admin&pass=x'y"
+
+	req, _ := rawhttp.NewRawHttpRequest(raw)
+
+	cmd := req.Curl()
+	expected := `curl https://example.com/login -H 'Content-Type: application/x-www-form-urlencoded' -H 'X-Test: it'\''s' -b a=1 --data-raw 'user=admin&pass=x'\''y'`
+	if cmd != expected {
+		t.Fatalf("unexpected curl command\n%v\n%v", cmd, expected)
+	}
+
+	// round trip
+	again, err := rawhttp.NewRawHttpRequestFromCurl(cmd)
+	if err != nil {
+		t.Fatalf("failed to parse exported command %v", err)
+	}
+	if again.Body != req.Body || again.Headers["x-test"] != "it's" || again.Cookies["a"] != "1" {
+		t.Errorf("round trip mismatch %v", again)
+	}
+}
+
+func Test_CurlHeaderValues(t *testing.T) {
+	cmd := "curl http://example.com:8080/api -e https://ref.com/x -H 'Origin: http://example.com:8080' -H 'X-Time: 10:20:30' --data-binary $'line1\\nline2\\n'"
+
+	req, err := rawhttp.NewRawHttpRequestFromCurl(cmd)
+	if err != nil {
+		t.Fatalf("failed to parse curl command %v", err)
+	}
+
+	// values containing ':' are kept
+	if req.Headers["referer"] != "https://ref.com/x" || req.Headers["origin"] != "http://example.com:8080" || req.Headers["x-time"] != "10:20:30" {
+		t.Errorf("unexpected headers %v", req.Headers)
+	}
+	// trailing newline of body is kept
+	if req.Body != "line1\nline2\n" {
+		t.Errorf("unexpected body %q", req.Body)
+	}
+	if req.Scheme != "http" || req.Host != "example.com:8080" || req.RawURL != "http://example.com:8080/api" {
+		t.Errorf("unexpected url %v %v %v", req.Scheme, req.Host, req.RawURL)
+	}
+
+	// round trip keeps scheme
+	cmd = req.Curl()
+	if !strings.HasPrefix(cmd, "curl http://example.com:8080/api ") {
+		t.Errorf("scheme not kept in exported command %v", cmd)
+	}
+	again, err := rawhttp.NewRawHttpRequestFromCurl(cmd)
+	if err != nil {
+		t.Fatalf("failed to parse exported command %v", err)
+	}
+	if again.Scheme != "http" || again.Body != req.Body || again.Headers["referer"] != req.Headers["referer"] || again.Headers["origin"] != req.Headers["origin"] {
+		t.Errorf("round trip mismatch %v", again)
+	}
+	if r, err := again.BuildRequest(); err != nil || r.URL.String() != "http://example.com:8080/api" {
+		t.Errorf("unexpected request url %v %v", r, err)
+	}
+}
+
+func Test_CurlHead(t *testing.T) {
+	req, err := rawhttp.NewRawHttpRequest("HEAD /status HTTP/1.1\nHost: example.com\n\n")
+	if err != nil {
+		t.Fatalf("failed to parse request %v", err)
+	}
+
+	cmd := req.Curl()
+	if cmd != "curl -I https://example.com/status" {
+		t.Fatalf("unexpected curl command %v", cmd)
+	}
+
+	// round trip
+	again, err := rawhttp.NewRawHttpRequestFromCurl(cmd)
+	if err != nil {
+		t.Fatalf("failed to parse exported command %v", err)
+	}
+	if again.Verb != "HEAD" || again.Path != "/status" || again.HasBody {
+		t.Errorf("round trip mismatch %v %v", again.Verb, again.Path)
+	}
+}
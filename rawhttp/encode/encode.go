@@ -0,0 +1,201 @@
+package encode
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+/*
+Payload Encoders
+
+Every encoder works on complete payload and is deterministic
+so that same payload always produces same output across tools
+
+Encoders can be chained using Pipeline (see pipeline.go)
+*/
+
+const upperhex = "0123456789ABCDEF"
+
+// isUnreserved : characters that are never url encoded (RFC 3986)
+func isUnreserved(c byte) bool {
+	return ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') ||
+		c == '-' || c == '_' || c == '.' || c == '~'
+}
+
+// URL : Percent encode all characters except unreserved characters
+func URL(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isUnreserved(c) {
+			sb.WriteByte(c)
+		} else {
+			sb.WriteByte('%')
+			sb.WriteByte(upperhex[c>>4])
+			sb.WriteByte(upperhex[c&15])
+		}
+	}
+	return sb.String()
+}
+
+// URLAll : Percent encode every character (including unreserved)
+func URLAll(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		sb.WriteByte('%')
+		sb.WriteByte(upperhex[s[i]>>4])
+		sb.WriteByte(upperhex[s[i]&15])
+	}
+	return sb.String()
+}
+
+// DoubleURL : URL encode twice
+func DoubleURL(s string) string {
+	return URL(URL(s))
+}
+
+// Unicode : Escape every character as \uXXXX (surrogate pairs for non-BMP)
+func Unicode(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r > 0xFFFF {
+			r -= 0x10000
+			fmt.Fprintf(&sb, "\\u%04x\\u%04x", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+			continue
+		}
+		fmt.Fprintf(&sb, "\\u%04x", r)
+	}
+	return sb.String()
+}
+
+// HTML : Encode HTML special characters using named entities
+func HTML(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch r {
+		case '&':
+			sb.WriteString("&amp;")
+		case '<':
+			sb.WriteString("&lt;")
+		case '>':
+			sb.WriteString("&gt;")
+		case '"':
+			sb.WriteString("&quot;")
+		case '\'':
+			sb.WriteString("&#39;")
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// HTMLAll : Encode every character as decimal HTML entity
+func HTMLAll(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		fmt.Fprintf(&sb, "&#%d;", r)
+	}
+	return sb.String()
+}
+
+// HTMLHex : Encode every character as hex HTML entity
+func HTMLHex(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		fmt.Fprintf(&sb, "&#x%x;", r)
+	}
+	return sb.String()
+}
+
+// Base64 : Standard base64 encoding
+func Base64(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// Base64URL : URL safe base64 encoding without padding
+func Base64URL(s string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(s))
+}
+
+// Hex : Hex encoding (lowercase)
+func Hex(s string) string {
+	return hex.EncodeToString([]byte(s))
+}
+
+// HexEscape : Escape every byte as \xHH
+func HexEscape(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		fmt.Fprintf(&sb, "\\x%02x", s[i])
+	}
+	return sb.String()
+}
+
+// JSString : Escape payload to be placed inside a javascript string literal
+func JSString(s string) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch r {
+		case '\\':
+			sb.WriteString("\\\\")
+		case '"':
+			sb.WriteString("\\\"")
+		case '\'':
+			sb.WriteString("\\'")
+		case '\n':
+			sb.WriteString("\\n")
+		case '\r':
+			sb.WriteString("\\r")
+		case '\t':
+			sb.WriteString("\\t")
+		case '<', '>', '&', '/':
+			// avoid breaking out of <script> blocks
+			fmt.Fprintf(&sb, "\\x%02x", r)
+		default:
+			if _, size := utf8.DecodeRuneInString(s[i:]); r == utf8.RuneError && size == 1 {
+				// invalid utf-8 byte
+				fmt.Fprintf(&sb, "\\x%02x", s[i])
+			} else if r < 0x20 {
+				fmt.Fprintf(&sb, "\\x%02x", r)
+			} else if r > 0x7E {
+				sb.WriteString(Unicode(string(r)))
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	return sb.String()
+}
+
+// Upper : Upper case
+func Upper(s string) string {
+	return strings.ToUpper(s)
+}
+
+// Lower : Lower case
+func Lower(s string) string {
+	return strings.ToLower(s)
+}
+
+// AlternateCase : Alternate case of letters starting with upper case (ex: ScRiPt)
+func AlternateCase(s string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range s {
+		if ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') {
+			if upper {
+				sb.WriteString(strings.ToUpper(string(r)))
+			} else {
+				sb.WriteString(strings.ToLower(string(r)))
+			}
+			upper = !upper
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
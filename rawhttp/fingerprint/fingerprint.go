@@ -0,0 +1,474 @@
+package fingerprint
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/html"
+)
+
+/*
+Technology Fingerprinting
+
+Identifies server software ,frameworks ,CMS ,WAF/CDN and JS libraries
+using wappalyzer compatible signature database
+
+Supported wappalyzer fields
+cats ,website ,headers ,cookies ,html ,scriptSrc (scripts) ,meta ,url ,implies
+Patterns support wappalyzer tags `\;version:\1` and `\;confidence:50`
+
+Extra (non-wappalyzer) field
+favicon => list of favicon hashes (mmh3 hash same as shodan http.favicon.hash)
+
+Note: wappalyzer patterns are javascript regexes . Patterns that are
+not supported by go regexp (ex: lookaheads) are skipped
+*/
+
+//go:embed technologies.json
+var defaultDatabase []byte
+
+// Category : Technology Category
+type Category struct {
+	Name string `json:"name"`
+}
+
+// Technology : Signature of a technology
+type Technology struct {
+	Name       string
+	Website    string
+	Categories []int
+	Headers    map[string][]*Pattern
+	Cookies    map[string][]*Pattern
+	Meta       map[string][]*Pattern
+	HTML       []*Pattern
+	ScriptSrc  []*Pattern
+	URL        []*Pattern
+	Implies    []*Pattern // Raw is name of implied technology
+	Favicon    []int32
+}
+
+// Pattern : Wappalyzer pattern
+type Pattern struct {
+	Raw        string
+	Regex      *regexp.Regexp // nil if pattern only checks presence
+	Version    string         // version template (ex: \1)
+	Confidence int            // Default: 100
+}
+
+// Match : Detected technology
+type Match struct {
+	Name       string
+	Version    string
+	Confidence int      // 0-100
+	Categories []string // Category names
+	Website    string
+	ImpliedBy  string // Name of technology which implied this (empty if detected)
+}
+
+// Input : Data used for fingerprinting
+type Input struct {
+	URL         string
+	Headers     http.Header
+	Cookies     map[string]string // cookie name => value
+	Body        []byte
+	ScriptSrc   []string            // Script sources (extracted from body if nil)
+	Meta        map[string][]string // meta name => content (extracted from body if nil)
+	FaviconHash *int32              // Favicon hash (see FaviconHash)
+}
+
+// Engine : Fingerprinting Engine
+type Engine struct {
+	Technologies map[string]*Technology
+	Categories   map[int]Category
+}
+
+// wappalyzer json schema (fields can be string ,array or object)
+type rawTechnology struct {
+	Cats      []int                      `json:"cats"`
+	Website   string                     `json:"website"`
+	Headers   map[string]json.RawMessage `json:"headers"`
+	Cookies   map[string]json.RawMessage `json:"cookies"`
+	Meta      map[string]json.RawMessage `json:"meta"`
+	HTML      json.RawMessage            `json:"html"`
+	ScriptSrc json.RawMessage            `json:"scriptSrc"`
+	Scripts   json.RawMessage            `json:"scripts"`
+	URL       json.RawMessage            `json:"url"`
+	Implies   json.RawMessage            `json:"implies"`
+	Favicon   []int32                    `json:"favicon"`
+}
+
+type rawDatabase struct {
+	Categories   map[string]Category      `json:"categories"`
+	Technologies map[string]rawTechnology `json:"technologies"`
+}
+
+// LoadDatabase : Load wappalyzer compatible json database
+// Both {"categories":..,"technologies":..} and plain {"name": {..}} technologies are accepted
+func LoadDatabase(data []byte) (*Engine, error) {
+	var db rawDatabase
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, fmt.Errorf("failed to parse database %v", err)
+	}
+
+	if db.Technologies == nil {
+		// plain technologies file
+		if err := json.Unmarshal(data, &db.Technologies); err != nil {
+			return nil, fmt.Errorf("failed to parse technologies %v", err)
+		}
+	}
+
+	e := &Engine{
+		Technologies: map[string]*Technology{},
+		Categories:   map[int]Category{},
+	}
+
+	for k, v := range db.Categories {
+		id, err := strconv.Atoi(k)
+		if err != nil {
+			continue
+		}
+		e.Categories[id] = v
+	}
+
+	for name, raw := range db.Technologies {
+		t := &Technology{
+			Name:       name,
+			Website:    raw.Website,
+			Categories: raw.Cats,
+			Headers:    parsePatternMap(raw.Headers),
+			Cookies:    parsePatternMap(raw.Cookies),
+			Meta:       parsePatternMap(raw.Meta),
+			HTML:       parsePatterns(raw.HTML),
+			ScriptSrc:  append(parsePatterns(raw.ScriptSrc), parsePatterns(raw.Scripts)...),
+			URL:        parsePatterns(raw.URL),
+			Implies:    parseImplies(raw.Implies),
+			Favicon:    raw.Favicon,
+		}
+		e.Technologies[name] = t
+	}
+
+	return e, nil
+}
+
+// LoadDatabaseFile : Load wappalyzer compatible json database from file
+func LoadDatabaseFile(file string) (*Engine, error) {
+	bin, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return LoadDatabase(bin)
+}
+
+// Default : Engine using embedded database
+func Default() *Engine {
+	e, err := LoadDatabase(defaultDatabase)
+	if err != nil {
+		// embedded database is tested
+		panic(err)
+	}
+	return e
+}
+
+// AnalyzeResponse : Fingerprint technologies using response
+func (e *Engine) AnalyzeResponse(r *rawhttp.RawHttpResponse) []Match {
+	in := Input{
+		Headers: r.Headers,
+		Cookies: map[string]string{},
+		Body:    r.Body,
+	}
+	for k, v := range r.Cookies {
+		in.Cookies[k] = v.Value
+	}
+	if r.Response != nil && r.Response.Request != nil && r.Response.Request.URL != nil {
+		in.URL = r.Response.Request.URL.String()
+	}
+	return e.Analyze(in)
+}
+
+// AnalyzeFavicon : Fingerprint technologies using favicon
+func (e *Engine) AnalyzeFavicon(icon []byte) []Match {
+	hash := FaviconHash(icon)
+	return e.Analyze(Input{FaviconHash: &hash})
+}
+
+// Analyze : Fingerprint technologies (sorted by name)
+func (e *Engine) Analyze(in Input) []Match {
+	if in.Body != nil && (in.ScriptSrc == nil || in.Meta == nil) {
+		if doc, err := html.NewDocument(in.Body, in.URL); err == nil {
+			if in.ScriptSrc == nil {
+				in.ScriptSrc = []string{}
+				for _, s := range doc.Scripts() {
+					if s.Src != "" {
+						in.ScriptSrc = append(in.ScriptSrc, s.Src)
+					}
+				}
+			}
+			if in.Meta == nil {
+				in.Meta = map[string][]string{}
+				for _, m := range doc.Meta() {
+					if m.Name != "" {
+						name := strings.ToLower(m.Name)
+						in.Meta[name] = append(in.Meta[name], m.Content)
+					}
+				}
+			}
+		}
+	}
+
+	body := string(in.Body)
+	detected := map[string]*Match{}
+
+	for name, t := range e.Technologies {
+		m := &Match{Name: name, Website: t.Website}
+		found := false
+
+		apply := func(p *Pattern, value string) {
+			if ok, version := p.match(value); ok {
+				found = true
+				m.Confidence += p.Confidence
+				if version != "" && (m.Version == "" || len(version) > len(m.Version)) {
+					m.Version = version
+				}
+			}
+		}
+
+		for hname, patterns := range t.Headers {
+			for _, v := range in.Headers.Values(hname) {
+				for _, p := range patterns {
+					apply(p, v)
+				}
+			}
+		}
+
+		for cname, patterns := range t.Cookies {
+			for k, v := range in.Cookies {
+				if strings.EqualFold(k, cname) {
+					for _, p := range patterns {
+						apply(p, v)
+					}
+				}
+			}
+		}
+
+		for mname, patterns := range t.Meta {
+			for _, v := range in.Meta[strings.ToLower(mname)] {
+				for _, p := range patterns {
+					apply(p, v)
+				}
+			}
+		}
+
+		if body != "" {
+			for _, p := range t.HTML {
+				apply(p, body)
+			}
+		}
+
+		for _, src := range in.ScriptSrc {
+			for _, p := range t.ScriptSrc {
+				apply(p, src)
+			}
+		}
+
+		if in.URL != "" {
+			for _, p := range t.URL {
+				apply(p, in.URL)
+			}
+		}
+
+		if in.FaviconHash != nil {
+			for _, h := range t.Favicon {
+				if h == *in.FaviconHash {
+					found = true
+					m.Confidence += 100
+				}
+			}
+		}
+
+		if found {
+			if m.Confidence > 100 {
+				m.Confidence = 100
+			}
+			detected[name] = m
+		}
+	}
+
+	// resolve implied technologies
+	queue := []string{}
+	for name := range detected {
+		queue = append(queue, name)
+	}
+	sort.Strings(queue)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, p := range e.Technologies[name].Implies {
+			implied := p.Raw
+			if _, ok := e.Technologies[implied]; !ok {
+				continue
+			}
+			if _, ok := detected[implied]; ok {
+				continue
+			}
+			conf := p.Confidence
+			if detected[name].Confidence < conf {
+				conf = detected[name].Confidence
+			}
+			detected[implied] = &Match{Name: implied, Confidence: conf, ImpliedBy: name, Website: e.Technologies[implied].Website}
+			queue = append(queue, implied)
+		}
+	}
+
+	matches := []Match{}
+	for name, m := range detected {
+		for _, c := range e.Technologies[name].Categories {
+			if cat, ok := e.Categories[c]; ok {
+				m.Categories = append(m.Categories, cat.Name)
+			}
+		}
+		matches = append(matches, *m)
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+
+	return matches
+}
+
+/* Pattern Parsing */
+
+var versionGroup = regexp.MustCompile(`\\(\d)`)
+
+// ParsePattern : Parse wappalyzer pattern (ex: nginx(?:/([\d.]+))?\;version:\1)
+// nil is returned if regex is not supported
+func ParsePattern(raw string) *Pattern {
+	p := parseTags(raw)
+
+	if p.Raw != "" {
+		re, err := regexp.Compile("(?i)" + p.Raw)
+		if err != nil {
+			return nil
+		}
+		p.Regex = re
+	}
+
+	return p
+}
+
+// parseTags : split pattern and its tags (version ,confidence)
+func parseTags(raw string) *Pattern {
+	parts := strings.Split(raw, `\;`)
+	p := &Pattern{Raw: parts[0], Confidence: 100}
+
+	for _, tag := range parts[1:] {
+		kv := strings.SplitN(tag, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		switch kv[0] {
+		case "version":
+			p.Version = kv[1]
+		case "confidence":
+			if c, err := strconv.Atoi(kv[1]); err == nil {
+				p.Confidence = c
+			}
+		}
+	}
+
+	return p
+}
+
+// match : check if value matches pattern and return version (if any)
+func (p *Pattern) match(value string) (bool, string) {
+	if p.Regex == nil {
+		// presence of header/cookie is enough
+		return true, ""
+	}
+
+	groups := p.Regex.FindStringSubmatch(value)
+	if groups == nil {
+		return false, ""
+	}
+	if p.Version == "" {
+		return true, ""
+	}
+
+	return true, resolveVersion(p.Version, groups)
+}
+
+// resolveVersion : replace \1 with groups and resolve ternary (\1?a:b)
+func resolveVersion(template string, groups []string) string {
+	if strings.Contains(template, "?") {
+		kv := strings.SplitN(template, "?", 2)
+		choices := strings.SplitN(kv[1], ":", 2)
+		cond := resolveVersion(kv[0], groups)
+		if cond != "" {
+			template = choices[0]
+		} else if len(choices) == 2 {
+			template = choices[1]
+		} else {
+			template = ""
+		}
+	}
+
+	v := versionGroup.ReplaceAllStringFunc(template, func(s string) string {
+		i, _ := strconv.Atoi(s[1:])
+		if i < len(groups) {
+			return groups[i]
+		}
+		return ""
+	})
+
+	return strings.TrimSpace(v)
+}
+
+// parsePatterns : string or array of patterns
+func parsePatterns(raw json.RawMessage) []*Pattern {
+	patterns := []*Pattern{}
+	for _, v := range stringOrSlice(raw) {
+		if p := ParsePattern(v); p != nil {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+// parseImplies : implies are technology names and not regexes
+func parseImplies(raw json.RawMessage) []*Pattern {
+	patterns := []*Pattern{}
+	for _, v := range stringOrSlice(raw) {
+		patterns = append(patterns, parseTags(v))
+	}
+	return patterns
+}
+
+func stringOrSlice(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}
+	}
+	values := []string{}
+	if err := json.Unmarshal(raw, &values); err != nil {
+		return nil
+	}
+	return values
+}
+
+func parsePatternMap(raw map[string]json.RawMessage) map[string][]*Pattern {
+	patterns := map[string][]*Pattern{}
+	for k, v := range raw {
+		if p := parsePatterns(v); len(p) > 0 {
+			patterns[k] = p
+		}
+	}
+	return patterns
+}
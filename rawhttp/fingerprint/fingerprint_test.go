@@ -0,0 +1,131 @@
+package fingerprint_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/fingerprint"
+)
+
+func find(matches []fingerprint.Match, name string) *fingerprint.Match {
+	for i := range matches {
+		if matches[i].Name == name {
+			return &matches[i]
+		}
+	}
+	return nil
+}
+
+func Test_Fingerprint_Response(t *testing.T) {
+	raw := "HTTP/1.1 200 OK\r\n" +
+		"Server: nginx/1.18.0\r\n" +
+		"X-Powered-By: PHP/7.4.3\r\n" +
+		"CF-RAY: 6f1c2d3e4f5a6b7c-FRA\r\n" +
+		"Set-Cookie: PHPSESSID=abc123; path=/\r\n" +
+		"Content-Type: text/html\r\n" +
+		"\r\n" +
+		`<html><head><meta name="generator" content="WordPress 6.1.1">` +
+		`<script src="/wp-includes/js/jquery/jquery.min.js?ver=3.6.1"></script></head><body></body></html>`
+
+	resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte(raw))
+	if err != nil {
+		t.Fatalf("failed to parse response %v", err)
+	}
+
+	matches := fingerprint.Default().AnalyzeResponse(resp)
+
+	expected := map[string]string{
+		"Nginx":      "1.18.0",
+		"PHP":        "7.4.3",
+		"WordPress":  "6.1.1",
+		"jQuery":     "3.6.1",
+		"Cloudflare": "",
+		"MySQL":      "",
+	}
+
+	for name, version := range expected {
+		m := find(matches, name)
+		if m == nil {
+			t.Errorf("%v was not detected %+v", name, matches)
+			continue
+		}
+		if m.Version != version {
+			t.Errorf("expected %v version %v but got %v", name, version, m.Version)
+		}
+	}
+
+	if m := find(matches, "MySQL"); m != nil && m.ImpliedBy != "WordPress" {
+		t.Errorf("expected MySQL to be implied by WordPress got %v", m.ImpliedBy)
+	}
+
+	if m := find(matches, "Nginx"); m != nil && (len(m.Categories) == 0 || m.Categories[0] != "Web servers") {
+		t.Errorf("unexpected categories %v", m.Categories)
+	}
+
+	if find(matches, "Microsoft IIS") != nil {
+		t.Errorf("false positive Microsoft IIS")
+	}
+}
+
+func Test_Fingerprint_CustomDatabase(t *testing.T) {
+	db := `{
+		"Acme": {
+			"cats": [1],
+			"headers": { "X-Acme": "^acme-(\\d+)\\;version:\\1\\;confidence:50" },
+			"cookies": { "acme_sid": "" },
+			"implies": "Base"
+		},
+		"Base": {},
+		"Lookahead": { "html": "foo(?=bar)" }
+	}`
+
+	e, err := fingerprint.LoadDatabase([]byte(db))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(e.Technologies["Lookahead"].HTML) != 0 {
+		t.Errorf("unsupported regex should be skipped")
+	}
+
+	headers := http.Header{}
+	headers.Set("x-acme", "ACME-42")
+	matches := e.Analyze(fingerprint.Input{Headers: headers})
+
+	acme := find(matches, "Acme")
+	if acme == nil || acme.Version != "42" || acme.Confidence != 50 {
+		t.Fatalf("unexpected match %+v", acme)
+	}
+	base := find(matches, "Base")
+	if base == nil || base.ImpliedBy != "Acme" || base.Confidence != 50 {
+		t.Errorf("unexpected implied match %+v", base)
+	}
+
+	matches = e.Analyze(fingerprint.Input{Cookies: map[string]string{"acme_sid": "x"}})
+	if acme := find(matches, "Acme"); acme == nil || acme.Confidence != 100 {
+		t.Errorf("cookie presence not detected %+v", matches)
+	}
+}
+
+func Test_Fingerprint_Favicon(t *testing.T) {
+	// base64 of icon spans multiple lines
+	icon := make([]byte, 256)
+	for i := range icon {
+		icon[i] = byte(i)
+	}
+	if h := fingerprint.FaviconHash(icon); h != -757223386 {
+		t.Errorf("expected hash -757223386 but got %v", h)
+	}
+
+	e, err := fingerprint.LoadDatabase([]byte(`{"Icon": {"favicon": [-757223386]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if find(e.AnalyzeFavicon(icon), "Icon") == nil {
+		t.Errorf("favicon was not detected")
+	}
+	if find(e.AnalyzeFavicon([]byte("other")), "Icon") != nil {
+		t.Errorf("favicon false positive")
+	}
+}
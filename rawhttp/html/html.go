@@ -0,0 +1,487 @@
+package html
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	xhtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+/*
+HTML Analysis of Response Bodies
+
+Extracts commonly used data from html pages
+1. Title & Meta Tags
+2. Links & Resources (a ,link ,img ,script ,iframe etc)
+3. Inline & External Scripts
+4. Comments
+5. Forms with all inputs (including hidden csrf tokens)
+
+All URLs are resolved using base url of document
+(<base href> is respected if present)
+Forms can be directly converted into rawhttp.RawHttpRequest
+*/
+
+// Document : Parsed HTML Document
+type Document struct {
+	Root    *xhtml.Node // Root node of html tree
+	BaseURL *url.URL    // URL used to resolve relative urls (can be nil)
+}
+
+// Meta : <meta> tag
+type Meta struct {
+	Name      string // name or property attribute
+	HTTPEquiv string // http-equiv attribute
+	Charset   string // charset attribute
+	Content   string // content attribute
+}
+
+// Link : URL found in a tag attribute
+type Link struct {
+	Tag  string // Tag name (ex: a ,img ,script)
+	Attr string // Attribute containing url (ex: href ,src)
+	URL  string // Resolved URL
+	Raw  string // URL as present in document
+	Text string // Text of anchor tags
+}
+
+// Script : <script> tag
+type Script struct {
+	Src     string // Resolved URL of external script (empty for inline scripts)
+	Type    string // type attribute
+	Content string // Content of inline script
+}
+
+// Input : Input element of a form (input ,select ,textarea ,button)
+type Input struct {
+	Tag     string // input ,select ,textarea or button
+	Name    string
+	Type    string // type attribute (lowercase) , text if missing
+	Value   string // Value submitted by browser (selected option for select)
+	Checked bool   // checkbox/radio is checked
+	Options []string
+}
+
+// Form : <form> tag with all inputs
+type Form struct {
+	ID      string
+	Name    string
+	Action  string // Resolved Action URL (document url if missing)
+	Method  string // GET or POST (uppercase)
+	Enctype string // Content-Type used to submit form
+	Inputs  []Input
+}
+
+// lists of tag & attributes containing urls
+var urlAttrs = map[atom.Atom][]string{
+	atom.A:      {"href"},
+	atom.Area:   {"href"},
+	atom.Link:   {"href"},
+	atom.Img:    {"src", "data-src"},
+	atom.Script: {"src"},
+	atom.Iframe: {"src"},
+	atom.Frame:  {"src"},
+	atom.Embed:  {"src"},
+	atom.Source: {"src"},
+	atom.Video:  {"src", "poster"},
+	atom.Audio:  {"src"},
+	atom.Track:  {"src"},
+	atom.Object: {"data"},
+	atom.Form:   {"action"},
+	atom.Button: {"formaction"},
+	atom.Input:  {"formaction", "src"},
+}
+
+// NewDocument : Parse html body with given base url (can be empty)
+func NewDocument(body []byte, baseurl string) (*Document, error) {
+	root, err := xhtml.Parse(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Document{Root: root}
+
+	if baseurl != "" {
+		u, err := url.Parse(baseurl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid base url %v", err)
+		}
+		d.BaseURL = u
+	}
+
+	// <base href> overrides document url
+	if base := d.find(atom.Base); len(base) > 0 {
+		if href, ok := attr(base[0], "href"); ok {
+			d.BaseURL = d.resolveURL(href)
+		}
+	}
+
+	return d, nil
+}
+
+// NewDocumentFromResponse : Parse html body of response
+// URL of request is used as base url if original response is stored (see rawhttp.StoreResponse)
+func NewDocumentFromResponse(r *rawhttp.RawHttpResponse) (*Document, error) {
+	baseurl := ""
+	if r.Response != nil && r.Response.Request != nil && r.Response.Request.URL != nil {
+		baseurl = r.Response.Request.URL.String()
+	}
+	return NewDocument(r.Body, baseurl)
+}
+
+// Title : Title of page
+func (d *Document) Title() string {
+	for _, n := range d.find(atom.Title) {
+		return strings.TrimSpace(text(n))
+	}
+	return ""
+}
+
+// Meta : All meta tags
+func (d *Document) Meta() []Meta {
+	metas := []Meta{}
+	for _, n := range d.find(atom.Meta) {
+		m := Meta{}
+		m.Name, _ = attr(n, "name")
+		if m.Name == "" {
+			m.Name, _ = attr(n, "property")
+		}
+		m.HTTPEquiv, _ = attr(n, "http-equiv")
+		m.Charset, _ = attr(n, "charset")
+		m.Content, _ = attr(n, "content")
+		metas = append(metas, m)
+	}
+	return metas
+}
+
+// Links : All links & resources in document (deduplicated by tag ,attribute & url)
+func (d *Document) Links() []Link {
+	links := []Link{}
+	seen := map[string]bool{}
+
+	d.walk(func(n *xhtml.Node) {
+		if n.Type != xhtml.ElementNode {
+			return
+		}
+		for _, a := range urlAttrs[n.DataAtom] {
+			raw, ok := attr(n, a)
+			raw = strings.TrimSpace(raw)
+			if !ok || raw == "" || strings.HasPrefix(raw, "#") {
+				continue
+			}
+			l := Link{Tag: n.Data, Attr: a, Raw: raw, URL: raw}
+			if u := d.resolveURL(raw); u != nil {
+				l.URL = u.String()
+			}
+			if n.DataAtom == atom.A {
+				l.Text = strings.TrimSpace(text(n))
+			}
+			key := l.Tag + "|" + l.Attr + "|" + l.URL
+			if !seen[key] {
+				seen[key] = true
+				links = append(links, l)
+			}
+		}
+
+		// srcset contains multiple urls
+		if srcset, ok := attr(n, "srcset"); ok {
+			for _, candidate := range strings.Split(srcset, ",") {
+				fields := strings.Fields(candidate)
+				if len(fields) == 0 {
+					continue
+				}
+				l := Link{Tag: n.Data, Attr: "srcset", Raw: fields[0], URL: fields[0]}
+				if u := d.resolveURL(fields[0]); u != nil {
+					l.URL = u.String()
+				}
+				key := l.Tag + "|" + l.Attr + "|" + l.URL
+				if !seen[key] {
+					seen[key] = true
+					links = append(links, l)
+				}
+			}
+		}
+	})
+
+	return links
+}
+
+// Scripts : All inline & external scripts
+func (d *Document) Scripts() []Script {
+	scripts := []Script{}
+	for _, n := range d.find(atom.Script) {
+		s := Script{}
+		s.Type, _ = attr(n, "type")
+		if src, ok := attr(n, "src"); ok && strings.TrimSpace(src) != "" {
+			s.Src = src
+			if u := d.resolveURL(src); u != nil {
+				s.Src = u.String()
+			}
+		} else {
+			s.Content = text(n)
+		}
+		scripts = append(scripts, s)
+	}
+	return scripts
+}
+
+// Comments : All html comments
+func (d *Document) Comments() []string {
+	comments := []string{}
+	d.walk(func(n *xhtml.Node) {
+		if n.Type == xhtml.CommentNode {
+			comments = append(comments, n.Data)
+		}
+	})
+	return comments
+}
+
+// Forms : All forms with their inputs
+func (d *Document) Forms() []Form {
+	forms := []Form{}
+
+	for _, n := range d.find(atom.Form) {
+		f := Form{Method: "GET", Enctype: "application/x-www-form-urlencoded"}
+		f.ID, _ = attr(n, "id")
+		f.Name, _ = attr(n, "name")
+
+		if m, ok := attr(n, "method"); ok && strings.EqualFold(strings.TrimSpace(m), "post") {
+			f.Method = "POST"
+		}
+		if e, ok := attr(n, "enctype"); ok && e != "" {
+			f.Enctype = strings.ToLower(strings.TrimSpace(e))
+		}
+
+		action, _ := attr(n, "action")
+		if u := d.resolveURL(strings.TrimSpace(action)); u != nil {
+			f.Action = u.String()
+		} else {
+			f.Action = action
+		}
+
+		walkNode(n, func(c *xhtml.Node) {
+			if c.Type != xhtml.ElementNode {
+				return
+			}
+			switch c.DataAtom {
+			case atom.Input, atom.Button, atom.Textarea, atom.Select:
+				f.Inputs = append(f.Inputs, parseInput(c))
+			}
+		})
+
+		forms = append(forms, f)
+	}
+
+	return forms
+}
+
+// HiddenInputs : Hidden inputs of form (usually contains csrf tokens)
+func (f Form) HiddenInputs() []Input {
+	hidden := []Input{}
+	for _, v := range f.Inputs {
+		if v.Type == "hidden" {
+			hidden = append(hidden, v)
+		}
+	}
+	return hidden
+}
+
+// Values : Values that will be submitted by browser (without clicking any button)
+func (f Form) Values() url.Values {
+	vals := url.Values{}
+	for _, v := range f.Inputs {
+		if v.Name == "" {
+			continue
+		}
+		switch v.Type {
+		case "submit", "button", "reset", "image":
+			continue
+		case "checkbox", "radio":
+			if !v.Checked {
+				continue
+			}
+		}
+		vals.Add(v.Name, v.Value)
+	}
+	return vals
+}
+
+// Request : Convert form into raw request ready to submit
+func (f Form) Request() (*rawhttp.RawHttpRequest, error) {
+	u, err := url.Parse(f.Action)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("form action %v is not an absolute url", f.Action)
+	}
+
+	vals := f.Values()
+
+	if f.Method == "GET" {
+		// browser replaces query string of action
+		u.RawQuery = vals.Encode()
+		raw := "GET " + u.RequestURI() + " HTTP/1.1\nHost: " + u.Host + "\n\n"
+		return parseRequest(raw, u.Scheme)
+	}
+
+	body := &rawhttp.RequestBody{Type: rawhttp.FormBody, Form: vals}
+	contenttype := "application/x-www-form-urlencoded"
+
+	switch f.Enctype {
+	case "multipart/form-data":
+		body = &rawhttp.RequestBody{Type: rawhttp.MultipartBody}
+		for _, v := range f.Inputs {
+			if v.Name == "" {
+				continue
+			}
+			if v.Type == "file" {
+				body.Parts = append(body.Parts, &rawhttp.MultipartPart{Name: v.Name, FileName: "", ContentType: "application/octet-stream"})
+				continue
+			}
+			for _, val := range vals[v.Name] {
+				body.Parts = append(body.Parts, &rawhttp.MultipartPart{Name: v.Name, Content: val})
+			}
+			// avoid adding same values again for repeated names
+			delete(vals, v.Name)
+		}
+	case "text/plain":
+		lines := []string{}
+		for _, v := range f.Inputs {
+			for _, val := range vals[v.Name] {
+				lines = append(lines, v.Name+"="+val)
+			}
+			delete(vals, v.Name)
+		}
+		body = &rawhttp.RequestBody{Raw: strings.Join(lines, "\r\n")}
+		contenttype = "text/plain"
+	}
+
+	raw := "POST " + u.RequestURI() + " HTTP/1.1\nHost: " + u.Host + "\nContent-Type: " + contenttype + "\n\n"
+	req, err := parseRequest(raw, u.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	if err := req.SetBody(body); err != nil {
+		return nil, err
+	}
+	req.HasBody = true
+
+	return req, nil
+}
+
+// parseRequest : parse raw request using scheme of action
+// (position markers in action are not treated as template)
+func parseRequest(raw string, scheme string) (*rawhttp.RawHttpRequest, error) {
+	req := &rawhttp.RawHttpRequest{Scheme: scheme}
+	if err := req.Parse(raw); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+func parseInput(n *xhtml.Node) Input {
+	in := Input{Tag: n.Data}
+	in.Name, _ = attr(n, "name")
+	in.Type, _ = attr(n, "type")
+	in.Type = strings.ToLower(strings.TrimSpace(in.Type))
+	_, in.Checked = attr(n, "checked")
+
+	switch n.DataAtom {
+	case atom.Input:
+		if in.Type == "" {
+			in.Type = "text"
+		}
+		in.Value, _ = attr(n, "value")
+		if in.Type == "checkbox" || in.Type == "radio" {
+			if _, ok := attr(n, "value"); !ok {
+				in.Value = "on"
+			}
+		}
+	case atom.Button:
+		if in.Type == "" {
+			in.Type = "submit"
+		}
+		in.Value, _ = attr(n, "value")
+	case atom.Textarea:
+		in.Type = "textarea"
+		in.Value = text(n)
+	case atom.Select:
+		in.Type = "select"
+		selected := ""
+		found := false
+		walkNode(n, func(c *xhtml.Node) {
+			if c.Type != xhtml.ElementNode || c.DataAtom != atom.Option {
+				return
+			}
+			val, ok := attr(c, "value")
+			if !ok {
+				val = strings.TrimSpace(text(c))
+			}
+			in.Options = append(in.Options, val)
+			if _, sel := attr(c, "selected"); sel && !found {
+				selected, found = val, true
+			}
+		})
+		if !found && len(in.Options) > 0 {
+			selected = in.Options[0]
+		}
+		in.Value = selected
+	}
+
+	return in
+}
+
+/* helpers */
+
+func (d *Document) resolveURL(raw string) *url.URL {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil
+	}
+	if d.BaseURL == nil {
+		return u
+	}
+	return d.BaseURL.ResolveReference(u)
+}
+
+func (d *Document) walk(fn func(n *xhtml.Node)) {
+	walkNode(d.Root, fn)
+}
+
+func (d *Document) find(a atom.Atom) []*xhtml.Node {
+	nodes := []*xhtml.Node{}
+	d.walk(func(n *xhtml.Node) {
+		if n.Type == xhtml.ElementNode && n.DataAtom == a {
+			nodes = append(nodes, n)
+		}
+	})
+	return nodes
+}
+
+func walkNode(n *xhtml.Node, fn func(n *xhtml.Node)) {
+	fn(n)
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		walkNode(c, fn)
+	}
+}
+
+func attr(n *xhtml.Node, key string) (string, bool) {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val, true
+		}
+	}
+	return "", false
+}
+
+func text(n *xhtml.Node) string {
+	var sb strings.Builder
+	walkNode(n, func(c *xhtml.Node) {
+		if c.Type == xhtml.TextNode {
+			sb.WriteString(c.Data)
+		}
+	})
+	return sb.String()
+}
@@ -0,0 +1,148 @@
+package html_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/html"
+)
+
+const page = `<!DOCTYPE html>
+<html>
+<head>
+	<title> Login Page </title>
+	<meta name="generator" content="WordPress 6.1">
+	<meta charset="utf-8">
+	<link rel="stylesheet" href="/static/app.css">
+	<script src="js/app.js"></script>
+	<script>var api = "/api/v1";</script>
+</head>
+<body>
+	<!-- TODO: remove debug endpoint /debug -->
+	<a href="/about">About Us</a>
+	<a href="https://cdn.example.org/x">CDN</a>
+	<img src="logo.png" srcset="logo@2x.png 2x, logo@3x.png 3x">
+	<form action="/login?from=home" method="post">
+		<input type="hidden" name="csrf" value="tok123">
+		<input name="user" value="">
+		<input type="password" name="pass">
+		<input type="checkbox" name="remember" checked>
+		<input type="checkbox" name="newsletter">
+		<select name="lang"><option value="en">English</option><option value="de" selected>German</option></select>
+		<textarea name="note">hi</textarea>
+		<button type="submit" name="go" value="1">Login</button>
+	</form>
+	<form action="search"><input name="q" value="test"></form>
+</body>
+</html>`
+
+func Test_Document(t *testing.T) {
+	doc, err := html.NewDocument([]byte(page), "https://example.com/account/")
+	if err != nil {
+		t.Fatalf("failed to parse html %v", err)
+	}
+
+	if doc.Title() != "Login Page" {
+		t.Errorf("unexpected title %q", doc.Title())
+	}
+
+	metas := doc.Meta()
+	if len(metas) != 2 || metas[0].Name != "generator" || metas[1].Charset != "utf-8" {
+		t.Errorf("unexpected meta tags %v", metas)
+	}
+
+	urls := []string{}
+	for _, l := range doc.Links() {
+		urls = append(urls, l.URL)
+	}
+	expected := []string{
+		"https://example.com/static/app.css",
+		"https://example.com/account/js/app.js",
+		"https://example.com/about",
+		"https://cdn.example.org/x",
+		"https://example.com/account/logo.png",
+		"https://example.com/account/logo@2x.png",
+		"https://example.com/account/logo@3x.png",
+		"https://example.com/login?from=home",
+		"https://example.com/account/search",
+	}
+	if strings.Join(urls, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected links\n%v", strings.Join(urls, "\n"))
+	}
+
+	scripts := doc.Scripts()
+	if len(scripts) != 2 || scripts[0].Src != "https://example.com/account/js/app.js" || !strings.Contains(scripts[1].Content, "/api/v1") {
+		t.Errorf("unexpected scripts %v", scripts)
+	}
+
+	if c := doc.Comments(); len(c) != 1 || !strings.Contains(c[0], "/debug") {
+		t.Errorf("unexpected comments %v", c)
+	}
+}
+
+func Test_FormRequest(t *testing.T) {
+	doc, _ := html.NewDocument([]byte(page), "https://example.com/account/")
+
+	forms := doc.Forms()
+	if len(forms) != 2 {
+		t.Fatalf("expected 2 forms got %v", len(forms))
+	}
+
+	login := forms[0]
+	if login.Method != "POST" || len(login.HiddenInputs()) != 1 || login.HiddenInputs()[0].Value != "tok123" {
+		t.Errorf("unexpected login form %+v", login)
+	}
+
+	req, err := login.Request()
+	if err != nil {
+		t.Fatalf("failed to convert form %v", err)
+	}
+
+	if req.Verb != "POST" || req.Host != "example.com" || req.Params.Get("from") != "home" {
+		t.Errorf("unexpected request line %v %v %v", req.Verb, req.Host, req.Path)
+	}
+
+	if req.Body != "csrf=tok123&lang=de&note=hi&pass=&remember=on&user=" || req.ContentType != "application/x-www-form-urlencoded" {
+		t.Errorf("unexpected body %v", req.Body)
+	}
+
+	search, _ := forms[1].Request()
+	if search.Verb != "GET" || search.Params.Get("q") != "test" || search.HasBody {
+		t.Errorf("unexpected search request %v", search)
+	}
+}
+
+func Test_MultipartForm(t *testing.T) {
+	body := `<form action="https://example.com/upload" method="POST" enctype="multipart/form-data">
+		<input name="title" value="x"><input type="file" name="doc"></form>`
+
+	doc, _ := html.NewDocument([]byte(body), "")
+	req, err := doc.Forms()[0].Request()
+	if err != nil {
+		t.Fatalf("failed to convert form %v", err)
+	}
+
+	parsed, err := req.ParseBody()
+	if err != nil || parsed.Type != rawhttp.MultipartBody || len(parsed.Parts) != 2 {
+		t.Fatalf("unexpected multipart body %v %v", parsed, err)
+	}
+}
+
+func Test_FormRequestScheme(t *testing.T) {
+	body := `<form action="http://example.com/search/§x§"><input name="q" value="{{FUZZ}}"></form>`
+
+	doc, _ := html.NewDocument([]byte(body), "")
+	req, err := doc.Forms()[0].Request()
+	if err != nil {
+		t.Fatalf("failed to convert form %v", err)
+	}
+
+	if req.Scheme != "http" || req.Template != nil || req.Params.Get("q") != "{{FUZZ}}" {
+		t.Errorf("unexpected request %v %v %v", req.Scheme, req.Template, req.Params)
+	}
+	r, err := req.BuildRequest()
+	if err != nil || r.URL.Scheme != "http" || r.URL.Host != "example.com" {
+		t.Errorf("unexpected url %v %v", r, err)
+	}
+}
@@ -0,0 +1,331 @@
+package rawhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"mime"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+/*
+In-place replacement of body fields
+
+Set() + SetBody() serialize whole body again . Form keys are sorted and
+JSON/XML are reformatted which changes every other field of body
+
+Functions below only replace value of a single field in raw body
+and keep everything else byte-identical
+Form      => value is url encoded
+JSON      => value is written as json string
+XML       => value is xml escaped
+Multipart => content and filename are written as-is
+*/
+
+// replaceField : Replace value of field at path in raw body
+// returns false if field cannot be located in raw body
+func replaceField(b *RequestBody, raw string, path string, value string) (string, bool) {
+	var start, end int
+	var replacement string
+	var ok bool
+
+	switch b.Type {
+	case FormBody:
+		start, end, ok = formValueSpan(raw, path)
+		replacement = url.QueryEscape(value)
+
+	case JSONBody:
+		start, end, ok = jsonValueSpan(raw, splitJSONPath(path))
+		replacement = jsonQuote(value)
+
+	case XMLBody:
+		return xmlReplace(raw, path, value)
+
+	case MultipartBody:
+		name, wantfile := splitPartPath(path)
+		start, end, ok = partSpan(raw, b.Boundary, name, wantfile)
+		replacement = value
+	}
+
+	if !ok {
+		return raw, false
+	}
+	return raw[:start] + replacement + raw[end:], true
+}
+
+// formValueSpan : position of value of first pair with given key
+func formValueSpan(raw string, key string) (int, int, bool) {
+	offset := 0
+	for _, pair := range strings.Split(raw, "&") {
+		k, _, found := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(k); err == nil && unescaped == key {
+			if !found {
+				// key without value (ex: a&b=1)
+				return offset + len(pair), offset + len(pair), true
+			}
+			return offset + len(k) + 1, offset + len(pair), true
+		}
+		offset += len(pair) + 1
+	}
+	return 0, 0, false
+}
+
+// jsonQuote : json string without escaping html characters (same as Encode)
+func jsonQuote(value string) string {
+	var buff bytes.Buffer
+	enc := json.NewEncoder(&buff)
+	enc.SetEscapeHTML(false)
+	enc.Encode(value)
+	return strings.TrimSuffix(buff.String(), "\n")
+}
+
+// jsonValueSpan : position of value at path in raw json
+func jsonValueSpan(raw string, path []string) (int, int, bool) {
+	type frame struct {
+		array   bool
+		key     string
+		index   int
+		wantkey bool
+	}
+
+	dec := json.NewDecoder(strings.NewReader(raw))
+	stack := []*frame{}
+	target := -1 // depth of container matched by path
+
+	// matches : current value is at path
+	matches := func() bool {
+		if len(stack) != len(path) {
+			return false
+		}
+		for i, f := range stack {
+			name := f.key
+			if f.array {
+				name = strconv.Itoa(f.index)
+			}
+			if name != path[i] {
+				return false
+			}
+		}
+		return true
+	}
+	// next : value of top container is complete
+	next := func() {
+		if len(stack) == 0 {
+			return
+		}
+		if top := stack[len(stack)-1]; top.array {
+			top.index++
+		} else {
+			top.wantkey = true
+		}
+	}
+
+	start := 0
+	for {
+		before := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err != nil {
+			return 0, 0, false
+		}
+		after := int(dec.InputOffset())
+
+		// token starts after whitespace and separators
+		pos := before
+		for pos < after && strings.IndexByte(" \t\r\n,:", raw[pos]) >= 0 {
+			pos++
+		}
+
+		if len(stack) > 0 {
+			if top := stack[len(stack)-1]; !top.array && top.wantkey {
+				if key, ok := tok.(string); ok {
+					top.key = key
+					top.wantkey = false
+					continue
+				}
+			}
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			if target < 0 && matches() {
+				target, start = len(stack), pos
+			}
+			stack = append(stack, &frame{array: tok == json.Delim('['), wantkey: true})
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			if target == len(stack) {
+				return start, after, true
+			}
+			next()
+		default:
+			if target < 0 && matches() {
+				return pos, after, true
+			}
+			next()
+		}
+	}
+}
+
+var xmlAttrRegex = regexp.MustCompile(`\s((?:[\w.-]+:)?[\w.-]+)\s*=\s*("[^"]*"|'[^']*')`)
+
+// xmlReplace : replace text of element or value of attribute at path in raw xml
+// (same lookup as RequestBody.Get : first element with given name at each level)
+func xmlReplace(raw string, path string, value string) (string, bool) {
+	elems := strings.Split(strings.Trim(path, "/"), "/")
+	attr := ""
+	if last := elems[len(elems)-1]; strings.HasPrefix(last, "@") {
+		attr = strings.TrimPrefix(last, "@")
+		elems = elems[:len(elems)-1]
+	}
+	if len(elems) == 0 {
+		return raw, false
+	}
+
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(value))
+
+	type element struct {
+		onpath bool
+		seen   map[string]bool
+	}
+
+	dec := xml.NewDecoder(strings.NewReader(raw))
+	dec.Strict = false
+	stack := []*element{}
+	textstart := -1
+
+	for {
+		before := int(dec.InputOffset())
+		tok, err := dec.RawToken()
+		if err != nil {
+			// io.EOF => element not found
+			return raw, false
+		}
+		after := int(dec.InputOffset())
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if textstart >= 0 {
+				// element has children (only leaf elements are replaced)
+				return raw, false
+			}
+			depth := len(stack)
+			name := xmlName(t.Name)
+			onpath := depth < len(elems) && name == elems[depth]
+			if depth > 0 {
+				parent := stack[depth-1]
+				onpath = onpath && parent.onpath && !parent.seen[name]
+				parent.seen[name] = true
+			}
+			stack = append(stack, &element{onpath: onpath, seen: map[string]bool{}})
+
+			if !onpath || depth != len(elems)-1 {
+				continue
+			}
+			tag := raw[before:after]
+			if attr != "" {
+				for _, m := range xmlAttrRegex.FindAllStringSubmatchIndex(tag, -1) {
+					qname := tag[m[2]:m[3]]
+					if qname == attr || strings.HasSuffix(qname, ":"+attr) {
+						// keep quotes
+						return raw[:before+m[4]+1] + escaped.String() + raw[before+m[5]-1:], true
+					}
+				}
+				return raw, false
+			}
+			if strings.HasSuffix(tag, "/>") {
+				// <name/> => <name>value</name>
+				open := strings.TrimRight(strings.TrimSuffix(tag, "/>"), " \t\r\n")
+				return raw[:before] + open + ">" + escaped.String() + "</" + name + ">" + raw[after:], true
+			}
+			textstart = after
+
+		case xml.EndElement:
+			if len(stack) == 0 {
+				return raw, false
+			}
+			stack = stack[:len(stack)-1]
+			if textstart >= 0 {
+				return raw[:textstart] + escaped.String() + raw[before:], true
+			}
+		}
+	}
+}
+
+// partSpan : position of content (or filename) of first part with given name in raw multipart body
+func partSpan(raw string, boundary string, name string, wantfile bool) (int, int, bool) {
+	delim := "--" + boundary
+	pos := strings.Index(raw, delim)
+	for pos >= 0 {
+		// headers start on next line
+		start := pos + len(delim)
+		if strings.HasPrefix(raw[start:], "--") {
+			return 0, 0, false
+		}
+		nl := strings.IndexByte(raw[start:], '\n')
+		if nl < 0 {
+			return 0, 0, false
+		}
+		start += nl + 1
+
+		next := strings.Index(raw[start:], delim)
+		if next < 0 {
+			return 0, 0, false
+		}
+		end := start + next
+		// line break before delimiter belongs to delimiter
+		if strings.HasSuffix(raw[:end], "\r\n") {
+			end -= 2
+		} else if strings.HasSuffix(raw[:end], "\n") {
+			end--
+		}
+
+		// empty content => blank line is followed by delimiter
+		content := -1
+		if i := strings.Index(raw[start:start+next], "\r\n\r\n"); i >= 0 {
+			content = start + i + 4
+		} else if i := strings.Index(raw[start:start+next], "\n\n"); i >= 0 {
+			content = start + i + 2
+		}
+		if content > end {
+			content = end
+		}
+		if content < 0 {
+			pos = start + next
+			continue
+		}
+
+		linestart := start
+		for _, line := range strings.SplitAfter(raw[start:content], "\n") {
+			k, v, _ := strings.Cut(line, ":")
+			if !strings.EqualFold(strings.TrimSpace(k), "content-disposition") {
+				linestart += len(line)
+				continue
+			}
+			_, params, err := mime.ParseMediaType(strings.TrimSpace(v))
+			if err != nil || params["name"] != name {
+				break
+			}
+			if !wantfile {
+				return content, end, true
+			}
+			// unquoted filenames are not located (body is serialized again)
+			i := strings.Index(line, `filename="`)
+			if i < 0 {
+				return 0, 0, false
+			}
+			vstart := linestart + i + len(`filename="`)
+			vend := strings.IndexByte(raw[vstart:content], '"')
+			if vend < 0 {
+				return 0, 0, false
+			}
+			return vstart, vstart + vend, true
+		}
+
+		pos = start + next
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,282 @@
+package rawhttp
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+/*
+Insertion Points (similar to burpsuite scanner)
+
+An insertion point is any location in a raw request where a payload
+can be injected
+1. Path Segments
+2. Query Parameters
+3. Cookie Values
+4. Header Values
+5. Body Fields (form ,json ,xml ,multipart)
+6. Multipart Filenames
+
+Inject() never modifies original request . A copy of request with
+payload at given insertion point is returned instead
+Payloads are injected as-is (no encoding is applied) except in path segments
+where characters that would change structure of url (?, #, invalid %, whitespace)
+are percent-encoded . Body fields are replaced in original body and rest of
+body is left unchanged
+*/
+
+// InsertionPointType : Location of insertion point
+type InsertionPointType int
+
+const (
+	PathInsertion              InsertionPointType = iota // Segment of URL Path
+	QueryInsertion                                       // Query Parameter Value
+	CookieInsertion                                      // Cookie Value
+	HeaderInsertion                                      // Header Value
+	FormInsertion                                        // urlencoded form value
+	JSONInsertion                                        // JSON Field Value
+	XMLInsertion                                         // XML element text or attribute value
+	MultipartInsertion                                   // Content of multipart part
+	MultipartFileNameInsertion                           // Filename of multipart part
+)
+
+// InsertionPoint : Location where payload can be injected
+type InsertionPoint struct {
+	Type  InsertionPointType
+	Name  string // Name of parameter/header/cookie or path of body field
+	Index int    // Index of path segment or index of value for repeated query params
+	Value string // Original Value at this location
+}
+
+// String : Human readable insertion point
+func (p InsertionPoint) String() string {
+	return fmt.Sprintf("%v:%v", InsertionPointTypeString(p.Type), p.Name)
+}
+
+// InsertionPointTypeString : Name of insertion point type
+func InsertionPointTypeString(z InsertionPointType) string {
+	switch z {
+	case PathInsertion:
+		return "Path"
+	case QueryInsertion:
+		return "Query"
+	case CookieInsertion:
+		return "Cookie"
+	case HeaderInsertion:
+		return "Header"
+	case FormInsertion:
+		return "Form"
+	case JSONInsertion:
+		return "JSON"
+	case XMLInsertion:
+		return "XML"
+	case MultipartInsertion:
+		return "Multipart"
+	case MultipartFileNameInsertion:
+		return "MultipartFileName"
+	default:
+		return "Invalid"
+	}
+}
+
+// splitPath : split path into path and raw query
+func (r *RawHttpRequest) splitPath() (string, string) {
+	path := r.Path
+	query := ""
+	if i := strings.Index(path, "?"); i >= 0 {
+		query = path[i:]
+		path = path[:i]
+	}
+	return path, query
+}
+
+// InsertionPoints : Get all insertion points of request
+func (r *RawHttpRequest) InsertionPoints() []InsertionPoint {
+	points := []InsertionPoint{}
+
+	// Path Segments
+	path, _ := r.splitPath()
+	for i, seg := range strings.Split(path, "/") {
+		if seg == "" {
+			continue
+		}
+		points = append(points, InsertionPoint{Type: PathInsertion, Name: seg, Index: i, Value: seg})
+	}
+
+	// Query Params
+	for _, k := range sortedKeys(r.Params) {
+		for i, v := range r.Params[k] {
+			points = append(points, InsertionPoint{Type: QueryInsertion, Name: k, Index: i, Value: v})
+		}
+	}
+
+	// Cookies
+	for _, k := range sortedKeys(r.Cookies) {
+		points = append(points, InsertionPoint{Type: CookieInsertion, Name: k, Value: r.Cookies[k]})
+	}
+
+	// Headers
+	for _, k := range sortedKeys(r.Headers) {
+		if strings.EqualFold(k, "content-type") {
+			// Overwritten by ContentType
+			continue
+		}
+		points = append(points, InsertionPoint{Type: HeaderInsertion, Name: k, Value: r.Headers[k]})
+	}
+
+	// Body
+	if r.Body == "" {
+		return points
+	}
+	body, err := r.ParseBody()
+	if err != nil {
+		return points
+	}
+	for _, p := range body.Paths() {
+		v, _ := body.Get(p)
+		point := InsertionPoint{Name: p, Value: v}
+		switch body.Type {
+		case FormBody:
+			point.Type = FormInsertion
+		case JSONBody:
+			point.Type = JSONInsertion
+		case XMLBody:
+			point.Type = XMLInsertion
+		case MultipartBody:
+			if strings.HasSuffix(p, "#filename") {
+				point.Type = MultipartFileNameInsertion
+			} else {
+				point.Type = MultipartInsertion
+			}
+		default:
+			continue
+		}
+		points = append(points, point)
+	}
+
+	return points
+}
+
+// Inject : Returns a copy of request with payload placed at insertion point
+func (r *RawHttpRequest) Inject(p InsertionPoint, payload string) (*RawHttpRequest, error) {
+	c := r.Clone()
+
+	switch p.Type {
+	case PathInsertion:
+		path, query := c.splitPath()
+		segments := strings.Split(path, "/")
+		if p.Index < 0 || p.Index >= len(segments) {
+			return nil, fmt.Errorf("path segment %v not found", p.Index)
+		}
+		segments[p.Index] = escapePathSegment(payload)
+		c.Path = strings.Join(segments, "/") + query
+		c.RawURL = c.baseURL() + c.Path
+
+	case QueryInsertion:
+		vals, ok := c.Params[p.Name]
+		if !ok || p.Index < 0 || p.Index >= len(vals) {
+			return nil, fmt.Errorf("query parameter %v not found", p.Name)
+		}
+		vals[p.Index] = payload
+
+	case CookieInsertion:
+		if _, ok := c.Cookies[p.Name]; !ok {
+			return nil, fmt.Errorf("cookie %v not found", p.Name)
+		}
+		c.Cookies[p.Name] = payload
+
+	case HeaderInsertion:
+		if _, ok := c.Headers[p.Name]; !ok {
+			return nil, fmt.Errorf("header %v not found", p.Name)
+		}
+		c.Headers[p.Name] = payload
+
+	case FormInsertion, JSONInsertion, XMLInsertion, MultipartInsertion, MultipartFileNameInsertion:
+		body, err := c.ParseBody()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := body.Get(p.Name); !ok {
+			return nil, fmt.Errorf("body field %v not found", p.Name)
+		}
+		if replaced, ok := replaceField(body, c.Body, p.Name, payload); ok {
+			c.Body = replaced
+			break
+		}
+		// field could not be located in raw body
+		if err := body.Set(p.Name, payload); err != nil {
+			return nil, err
+		}
+		if err := c.SetBody(body); err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("invalid insertion point %v", p)
+	}
+
+	return c, nil
+}
+
+// escapePathSegment : percent-encode characters which would move payload out of path
+// (../ and already encoded payloads like %2e%2e are kept as-is)
+func escapePathSegment(payload string) string {
+	var sb strings.Builder
+	for i := 0; i < len(payload); i++ {
+		c := payload[i]
+		switch {
+		case c == '%' && i+2 < len(payload) && isHex(payload[i+1]) && isHex(payload[i+2]):
+			sb.WriteByte(c)
+		case c == '%', c == '?', c == '#', c <= ' ', c == 0x7f:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+func isHex(c byte) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+
+// Clone : Deep copy of raw request
+func (r *RawHttpRequest) Clone() *RawHttpRequest {
+	c := *r
+
+	c.Params = url.Values{}
+	for k, v := range r.Params {
+		c.Params[k] = append([]string{}, v...)
+	}
+
+	c.Headers = make(map[string]string, len(r.Headers))
+	for k, v := range r.Headers {
+		c.Headers[k] = v
+	}
+
+	c.Cookies = make(map[string]string, len(r.Cookies))
+	for k, v := range r.Cookies {
+		c.Cookies[k] = v
+	}
+
+	return &c
+}
+
+// baseURL : scheme and host used while constructing request url
+func (r *RawHttpRequest) baseURL() string {
+	if r.PredefinedHost != "" {
+		return r.scheme() + "://" + r.PredefinedHost
+	}
+	return r.scheme() + "://" + r.Host
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,189 @@
+package rawhttp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+func Test_InsertionPoints(t *testing.T) {
+	raw := `POST /api/v1/users?id=5 HTTP/1.1
+Host: example.com
+User-Agent: test
+Cookie: session=abc
+Content-Type: application/json
+
+{"name":"bob"}`
+
+	req, err := rawhttp.NewRawHttpRequest(raw)
+	if err != nil {
+		t.Fatalf("failed to parse request %v", err)
+	}
+
+	points := req.InsertionPoints()
+
+	got := []string{}
+	for _, p := range points {
+		got = append(got, p.String())
+	}
+
+	expected := "Path:api,Path:v1,Path:users,Query:id,Cookie:session,Header:user-agent,JSON:name"
+	if strings.Join(got, ",") != expected {
+		t.Fatalf("unexpected insertion points %v", got)
+	}
+
+	for _, p := range points {
+		injected, err := req.Inject(p, "PAYLOAD")
+		if err != nil {
+			t.Errorf("failed to inject at %v %v", p, err)
+			continue
+		}
+
+		dump := injected.GetRequest()
+		found := strings.Contains(dump.URL.String(), "PAYLOAD") ||
+			strings.Contains(dump.Header.Get("Cookie"), "PAYLOAD") ||
+			dump.Header.Get("User-Agent") == "PAYLOAD" ||
+			strings.Contains(injected.Body, "PAYLOAD")
+
+		if !found {
+			t.Errorf("payload missing for %v", p)
+		}
+	}
+
+	// Original request must not change
+	if req.Params.Get("id") != "5" || req.Cookies["session"] != "abc" || req.Body != `{"name":"bob"}` {
+		t.Errorf("original request modified %v", req)
+	}
+}
+
+func Test_InjectPath(t *testing.T) {
+	req, err := rawhttp.NewRawHttpRequest("GET /api/users/5?id=1 HTTP/1.1\nHost: example.com\n\n")
+	if err != nil {
+		t.Fatalf("failed to parse request %v", err)
+	}
+	point := rawhttp.InsertionPoint{Type: rawhttp.PathInsertion, Index: 3}
+
+	expected := map[string]string{
+		"%zz":    "/api/users/%25zz",
+		"a?b#c":  "/api/users/a%3Fb%23c",
+		"../etc": "/api/users/../etc",
+		"%2e%2e": "/api/users/%2e%2e",
+		"a b":    "/api/users/a%20b",
+	}
+	for payload, path := range expected {
+		injected, err := req.Inject(point, payload)
+		if err != nil {
+			t.Fatalf("failed to inject %v %v", payload, err)
+		}
+		if injected.Path != path+"?id=1" {
+			t.Errorf("expected path %v for %v got %v", path, payload, injected.Path)
+		}
+		r, err := injected.BuildRequest()
+		if err != nil {
+			t.Fatalf("failed to build request for %v %v", payload, err)
+		}
+		if r.URL.Query().Get("id") != "1" {
+			t.Errorf("query changed for %v got %v", payload, r.URL.RawQuery)
+		}
+	}
+
+	// invalid path returns error instead of panic
+	bad := req.Clone()
+	bad.Path = "/%zz"
+	if _, err := bad.BuildRequest(); err == nil {
+		t.Errorf("expected error for invalid path")
+	}
+	if bad.GetRequest() != nil {
+		t.Errorf("expected nil request for invalid path")
+	}
+}
+
+func Test_InjectBody(t *testing.T) {
+	cases := []struct {
+		contenttype string
+		body        string
+		point       rawhttp.InsertionPoint
+		expected    string
+	}{
+		{
+			"application/x-www-form-urlencoded",
+			"zeta=1&alpha=2&token=a%2Bb",
+			rawhttp.InsertionPoint{Type: rawhttp.FormInsertion, Name: "alpha"},
+			"zeta=1&alpha=%3Cx%3E+%22&token=a%2Bb",
+		},
+		{
+			"application/json",
+			"{\n  \"zeta\": 1,\n  \"user\": {\"name\": \"bob\", \"tags\": [\"a\", \"b\"]},\n  \"alpha\": 2.50\n}",
+			rawhttp.InsertionPoint{Type: rawhttp.JSONInsertion, Name: "user.tags.1"},
+			"{\n  \"zeta\": 1,\n  \"user\": {\"name\": \"bob\", \"tags\": [\"a\", \"<x> \\\"\"]},\n  \"alpha\": 2.50\n}",
+		},
+		{
+			"application/json",
+			`{"zeta":1,"alpha":2.50}`,
+			rawhttp.InsertionPoint{Type: rawhttp.JSONInsertion, Name: "alpha"},
+			`{"zeta":1,"alpha":"<x> \""}`,
+		},
+		{
+			"application/xml",
+			"<?xml version=\"1.0\"?>\n<root>\n  <user id='7'>\n    <name>bob</name>\n    <empty/>\n  </user>\n</root>",
+			rawhttp.InsertionPoint{Type: rawhttp.XMLInsertion, Name: "root/user/name"},
+			"<?xml version=\"1.0\"?>\n<root>\n  <user id='7'>\n    <name>&lt;x&gt; &#34;</name>\n    <empty/>\n  </user>\n</root>",
+		},
+		{
+			"application/xml",
+			"<root>\n  <user id='7'>\n    <name>bob</name>\n  </user>\n</root>",
+			rawhttp.InsertionPoint{Type: rawhttp.XMLInsertion, Name: "root/user/@id"},
+			"<root>\n  <user id='&lt;x&gt; &#34;'>\n    <name>bob</name>\n  </user>\n</root>",
+		},
+		{
+			"application/xml",
+			"<root><user/><name>bob</name></root>",
+			rawhttp.InsertionPoint{Type: rawhttp.XMLInsertion, Name: "root/user"},
+			"<root><user>&lt;x&gt; &#34;</user><name>bob</name></root>",
+		},
+		{
+			"multipart/form-data; boundary=xyz",
+			"--xyz\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nhello\r\n" +
+				"--xyz\r\nContent-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\r\nContent-Type: text/plain\r\n\r\nfile content\r\n--xyz--",
+			rawhttp.InsertionPoint{Type: rawhttp.MultipartFileNameInsertion, Name: "file#filename"},
+			"--xyz\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nhello\r\n" +
+				"--xyz\r\nContent-Disposition: form-data; name=\"file\"; filename=\"<x> \"\"\r\nContent-Type: text/plain\r\n\r\nfile content\r\n--xyz--",
+		},
+		{
+			"multipart/form-data; boundary=xyz",
+			"--xyz\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nhello\r\n" +
+				"--xyz\r\nContent-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\r\nContent-Type: text/plain\r\n\r\nfile content\r\n--xyz--",
+			rawhttp.InsertionPoint{Type: rawhttp.MultipartInsertion, Name: "title"},
+			"--xyz\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\n<x> \"\r\n" +
+				"--xyz\r\nContent-Disposition: form-data; name=\"file\"; filename=\"a.txt\"\r\nContent-Type: text/plain\r\n\r\nfile content\r\n--xyz--",
+		},
+	}
+
+	for _, c := range cases {
+		req := &rawhttp.RawHttpRequest{
+			Verb:        "POST",
+			Path:        "/",
+			Host:        "example.com",
+			Headers:     map[string]string{},
+			Cookies:     map[string]string{},
+			ContentType: c.contenttype,
+			Body:        c.body,
+			HasBody:     true,
+		}
+		injected, err := req.Inject(c.point, `<x> "`)
+		if err != nil {
+			t.Errorf("failed to inject at %v %v", c.point, err)
+			continue
+		}
+		// rest of body must be byte-identical
+		if injected.Body != c.expected {
+			t.Errorf("unexpected body for %v\nexpected %q\ngot      %q", c.point, c.expected, injected.Body)
+		}
+		if body, err := injected.ParseBody(); err != nil {
+			t.Errorf("injected body of %v is invalid %v", c.point, err)
+		} else if v, _ := body.Get(c.point.Name); v != `<x> "` && c.point.Type != rawhttp.MultipartFileNameInsertion {
+			t.Errorf("expected payload at %v got %q", c.point, v)
+		}
+	}
+}
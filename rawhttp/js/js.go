@@ -0,0 +1,310 @@
+package js
+
+import (
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+/*
+Endpoint & Parameter Extraction from JavaScript
+
+Regex based extraction (similar to linkfinder) of
+1. Full URLs
+2. Relative/Absolute Paths in string literals
+3. Call sites of fetch() ,axios ,XMLHttpRequest.open() ,jQuery ajax
+4. GraphQL Operations (query/mutation/subscription)
+5. Likely Parameter Names (query strings ,URLSearchParams ,FormData ,GraphQL variables)
+
+All endpoints are deduplicated and resolved using base url (url of response)
+*/
+
+// EndpointType : How endpoint was found
+type EndpointType int
+
+const (
+	URLEndpoint    EndpointType = iota // Full URL in source
+	PathEndpoint                       // Path in string literal
+	FetchEndpoint                      // fetch() call
+	AxiosEndpoint                      // axios call
+	XHREndpoint                        // XMLHttpRequest.open() call
+	JQueryEndpoint                     // $.ajax ,$.get ,$.post etc
+)
+
+// EndpointTypeString : Name of endpoint type
+func EndpointTypeString(z EndpointType) string {
+	switch z {
+	case URLEndpoint:
+		return "URL"
+	case PathEndpoint:
+		return "Path"
+	case FetchEndpoint:
+		return "Fetch"
+	case AxiosEndpoint:
+		return "Axios"
+	case XHREndpoint:
+		return "XHR"
+	case JQueryEndpoint:
+		return "JQuery"
+	default:
+		return "Invalid"
+	}
+}
+
+// Endpoint : Endpoint found in javascript
+type Endpoint struct {
+	Type   EndpointType
+	Method string // HTTP Method (only if known from call site)
+	Raw    string // As present in source
+	URL    string // Resolved URL (same as Raw if base url is missing)
+	Source string // URL of response where endpoint was found
+}
+
+// GraphQLOperation : GraphQL Operation found in source
+type GraphQLOperation struct {
+	Type string // query ,mutation or subscription
+	Name string
+}
+
+// Result : Extraction results
+type Result struct {
+	Endpoints []Endpoint
+	GraphQL   []GraphQLOperation
+	Params    []string // Sorted & Unique parameter names
+
+	seen       map[string]int
+	seenparams map[string]bool
+	seengql    map[string]bool
+}
+
+var (
+	// call sites
+	fetchRegex  = regexp.MustCompile(`fetch\(\s*["'` + "`" + `]([^"'` + "`" + `]+)["'` + "`" + `](?:\s*,\s*\{[^}]*?method\s*:\s*["'` + "`" + `]([A-Za-z]+))?`)
+	axiosRegex  = regexp.MustCompile(`axios\.(get|post|put|delete|patch|head|options|request)\(\s*["'` + "`" + `]([^"'` + "`" + `]+)`)
+	axiosObj    = regexp.MustCompile(`axios(?:\.request)?\(\s*\{[^}]*?url\s*:\s*["'` + "`" + `]([^"'` + "`" + `]+)["'` + "`" + `]`)
+	xhrRegex    = regexp.MustCompile(`(?i)\.open\(\s*["'` + "`" + `](GET|POST|PUT|DELETE|PATCH|HEAD|OPTIONS)["'` + "`" + `]\s*,\s*["'` + "`" + `]([^"'` + "`" + `]+)`)
+	jqueryRegex = regexp.MustCompile(`\$\.(get|post|getJSON|ajax|load)\(\s*["'` + "`" + `]([^"'` + "`" + `]+)`)
+	jqueryObj   = regexp.MustCompile(`\$\.ajax\(\s*\{[^}]*?url\s*:\s*["'` + "`" + `]([^"'` + "`" + `]+)["'` + "`" + `]`)
+
+	// generic
+	urlRegex  = regexp.MustCompile(`(?:https?:)?//[a-zA-Z0-9][a-zA-Z0-9.\-]*\.[a-zA-Z]{2,}(?::\d+)?(?:/[^\s"'` + "`" + `<>()\\{}]*)?`)
+	pathRegex = regexp.MustCompile(`["'` + "`" + `]((?:/|\.\.?/)[a-zA-Z0-9_\-.~%/?&=:;@+!$,\[\]{}]{1,300}|[a-zA-Z0-9_\-]+/[a-zA-Z0-9_\-/.]*\.(?:php|asp|aspx|jsp|json|action|html|do|cgi|xml)(?:\?[^"'` + "`" + `\s]*)?)["'` + "`" + `]`)
+
+	// graphql
+	gqlRegex    = regexp.MustCompile(`\b(query|mutation|subscription)\s+([A-Za-z_][A-Za-z0-9_]*)\s*[({]`)
+	gqlVarRegex = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)\s*:\s*\[?[A-Z]`)
+
+	// params
+	queryParamRegex = regexp.MustCompile(`[?&]([a-zA-Z0-9_\-\[\]]{1,64})=`)
+	appendRegex     = regexp.MustCompile(`\.(?:append|set)\(\s*["'` + "`" + `]([a-zA-Z0-9_\-\[\]]{1,64})["'` + "`" + `]\s*,`)
+	getParamRegex   = regexp.MustCompile(`(?:searchParams|[pP]arams|query)\.(?:get|getAll|has)\(\s*["'` + "`" + `]([a-zA-Z0-9_\-\[\]]{1,64})["'` + "`" + `]`)
+)
+
+// Extract : Extract endpoints and params from javascript source
+func Extract(body []byte, baseurl string) *Result {
+	r := newResult()
+	r.extract(string(body), baseurl)
+	r.finalize()
+	return r
+}
+
+// ExtractFromResponse : Extract endpoints from response body
+// URL of request is used as base url if original response is stored (see rawhttp.StoreResponse)
+func ExtractFromResponse(resp *rawhttp.RawHttpResponse) *Result {
+	baseurl := ""
+	if resp.Response != nil && resp.Response.Request != nil && resp.Response.Request.URL != nil {
+		baseurl = resp.Response.Request.URL.String()
+	}
+	return Extract(resp.Body, baseurl)
+}
+
+// Merge : Merge other result into this result (deduplicated)
+func (r *Result) Merge(other *Result) {
+	if r.seen == nil {
+		r.rebuild()
+	}
+	for _, e := range other.Endpoints {
+		r.addEndpoint(e)
+	}
+	for _, g := range other.GraphQL {
+		r.addGraphQL(g.Type, g.Name)
+	}
+	for _, p := range other.Params {
+		r.addParam(p)
+	}
+	r.finalize()
+}
+
+// URLs : Unique resolved urls of all endpoints
+func (r *Result) URLs() []string {
+	urls := []string{}
+	for _, e := range r.Endpoints {
+		urls = append(urls, e.URL)
+	}
+	return urls
+}
+
+// IsJavaScript : Check if response contains javascript (using content-type and url)
+func IsJavaScript(contenttype string, rawurl string) bool {
+	contenttype = strings.ToLower(contenttype)
+	if strings.Contains(contenttype, "javascript") || strings.Contains(contenttype, "ecmascript") {
+		return true
+	}
+	if u, err := url.Parse(rawurl); err == nil {
+		p := strings.ToLower(u.Path)
+		return strings.HasSuffix(p, ".js") || strings.HasSuffix(p, ".mjs")
+	}
+	return false
+}
+
+func newResult() *Result {
+	return &Result{
+		Endpoints:  []Endpoint{},
+		GraphQL:    []GraphQLOperation{},
+		Params:     []string{},
+		seen:       map[string]int{},
+		seenparams: map[string]bool{},
+		seengql:    map[string]bool{},
+	}
+}
+
+func (r *Result) rebuild() {
+	endpoints, gql, params := r.Endpoints, r.GraphQL, r.Params
+	*r = *newResult()
+	for _, e := range endpoints {
+		r.addEndpoint(e)
+	}
+	for _, g := range gql {
+		r.addGraphQL(g.Type, g.Name)
+	}
+	for _, p := range params {
+		r.addParam(p)
+	}
+}
+
+func (r *Result) extract(src string, baseurl string) {
+	var base *url.URL
+	if baseurl != "" {
+		base, _ = url.Parse(baseurl)
+	}
+
+	add := func(t EndpointType, method string, raw string) {
+		raw = strings.TrimSpace(raw)
+		if raw == "" || raw == "/" || raw == "//" {
+			return
+		}
+		e := Endpoint{Type: t, Method: strings.ToUpper(method), Raw: raw, URL: raw, Source: baseurl}
+		if base != nil && !strings.Contains(raw, "${") {
+			if u, err := url.Parse(raw); err == nil {
+				e.URL = base.ResolveReference(u).String()
+			}
+		}
+		r.addEndpoint(e)
+
+		// parameter names from query string
+		for _, m := range queryParamRegex.FindAllStringSubmatch(raw, -1) {
+			r.addParam(m[1])
+		}
+	}
+
+	// Call sites first since they contain method
+	for _, m := range fetchRegex.FindAllStringSubmatch(src, -1) {
+		method := m[2]
+		if method == "" {
+			method = "GET"
+		}
+		add(FetchEndpoint, method, m[1])
+	}
+	for _, m := range axiosRegex.FindAllStringSubmatch(src, -1) {
+		method := m[1]
+		if method == "request" {
+			method = ""
+		}
+		add(AxiosEndpoint, method, m[2])
+	}
+	for _, m := range axiosObj.FindAllStringSubmatch(src, -1) {
+		add(AxiosEndpoint, "", m[1])
+	}
+	for _, m := range xhrRegex.FindAllStringSubmatch(src, -1) {
+		add(XHREndpoint, m[1], m[2])
+	}
+	for _, m := range jqueryRegex.FindAllStringSubmatch(src, -1) {
+		method := ""
+		switch m[1] {
+		case "get", "getJSON", "load":
+			method = "GET"
+		case "post":
+			method = "POST"
+		}
+		add(JQueryEndpoint, method, m[2])
+	}
+	for _, m := range jqueryObj.FindAllStringSubmatch(src, -1) {
+		add(JQueryEndpoint, "", m[1])
+	}
+
+	// generic urls & paths
+	for _, m := range urlRegex.FindAllString(src, -1) {
+		add(URLEndpoint, "", m)
+	}
+	for _, m := range pathRegex.FindAllStringSubmatch(src, -1) {
+		if strings.HasPrefix(m[1], "//") {
+			// protocol relative urls are already covered
+			continue
+		}
+		add(PathEndpoint, "", m[1])
+	}
+
+	// graphql
+	for _, m := range gqlRegex.FindAllStringSubmatch(src, -1) {
+		r.addGraphQL(m[1], m[2])
+	}
+	for _, m := range gqlVarRegex.FindAllStringSubmatch(src, -1) {
+		r.addParam(m[1])
+	}
+
+	// parameters
+	// URLSearchParams/FormData append() ,set() and get()
+	for _, m := range appendRegex.FindAllStringSubmatch(src, -1) {
+		r.addParam(m[1])
+	}
+	for _, m := range getParamRegex.FindAllStringSubmatch(src, -1) {
+		r.addParam(m[1])
+	}
+}
+
+// addEndpoint : endpoints are unique by url . Method is added if
+// endpoint was previously found without method
+func (r *Result) addEndpoint(e Endpoint) {
+	if i, ok := r.seen[e.URL]; ok {
+		if r.Endpoints[i].Method == "" && e.Method != "" {
+			r.Endpoints[i].Method = e.Method
+		}
+		return
+	}
+	r.seen[e.URL] = len(r.Endpoints)
+	r.Endpoints = append(r.Endpoints, e)
+}
+
+func (r *Result) addGraphQL(t string, name string) {
+	key := t + " " + name
+	if r.seengql[key] {
+		return
+	}
+	r.seengql[key] = true
+	r.GraphQL = append(r.GraphQL, GraphQLOperation{Type: t, Name: name})
+}
+
+func (r *Result) addParam(p string) {
+	if r.seenparams[p] {
+		return
+	}
+	r.seenparams[p] = true
+	r.Params = append(r.Params, p)
+}
+
+func (r *Result) finalize() {
+	sort.Strings(r.Params)
+}
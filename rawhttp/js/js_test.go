@@ -0,0 +1,64 @@
+package js_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/js"
+)
+
+const source = `
+const API = "https://api.example.com/v2/users?page=1";
+fetch("/api/orders?status=open", { method: "POST", body: data });
+axios.delete('/api/orders/1');
+var xhr = new XMLHttpRequest(); xhr.open("PUT", "profile/update.php");
+$.post("/legacy/save.do", {id: 1});
+const q = new URLSearchParams(); q.append("token", t);
+const doc = gql` + "`" + `query GetUser($userId: ID!) { user(id: $userId) { name } }` + "`" + `;
+import x from "./chunk.js";
+fetch("/api/orders?status=open");
+`
+
+func Test_Extract(t *testing.T) {
+	r := js.Extract([]byte(source), "https://example.com/static/app.js")
+
+	got := []string{}
+	for _, e := range r.Endpoints {
+		got = append(got, js.EndpointTypeString(e.Type)+" "+e.Method+" "+e.URL)
+	}
+
+	expected := []string{
+		"Fetch POST https://example.com/api/orders?status=open",
+		"Axios DELETE https://example.com/api/orders/1",
+		"XHR PUT https://example.com/static/profile/update.php",
+		"JQuery POST https://example.com/legacy/save.do",
+		"URL  https://api.example.com/v2/users?page=1",
+		"Path  https://example.com/static/chunk.js",
+	}
+
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected endpoints\n%v", strings.Join(got, "\n"))
+	}
+
+	if len(r.GraphQL) != 1 || r.GraphQL[0].Name != "GetUser" || r.GraphQL[0].Type != "query" {
+		t.Errorf("unexpected graphql operations %v", r.GraphQL)
+	}
+
+	if strings.Join(r.Params, ",") != "page,status,token,userId" {
+		t.Errorf("unexpected params %v", r.Params)
+	}
+}
+
+func Test_Merge(t *testing.T) {
+	a := js.Extract([]byte(`fetch("/a")`), "https://example.com/")
+	b := js.Extract([]byte(`var u = "/a"; var v = "/b?x=1"`), "https://example.com/")
+
+	a.Merge(b)
+
+	if strings.Join(a.URLs(), ",") != "https://example.com/a,https://example.com/b?x=1" {
+		t.Errorf("unexpected merged urls %v", a.URLs())
+	}
+	if strings.Join(a.Params, ",") != "x" {
+		t.Errorf("unexpected merged params %v", a.Params)
+	}
+}
@@ -0,0 +1,693 @@
+package rawhttp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+/*
+JSON Querying ,Schema Extraction & Diffing of response bodies
+
+Query Syntax (gjson style ,JSONPath is also accepted)
+  user.name            => field of object
+  items.0.id           => array index
+  items.#              => length of array
+  items.#.id           => id of every element (array of results)
+  items.*              => all values of object/array
+  items.#(role=="admin").id   => first element matching condition
+  items.#(age>=18)#.name      => all elements matching condition
+  $.items[0].id        => JSONPath (converted to items.0.id)
+  a\.b                 => key containing dot
+
+Supported operators in conditions: == != < <= > >= and % (glob match ex: name%"adm*")
+
+Schema contains key paths and types of all values . Array indices are
+generalized to # (ex: items.#.id) so paths can be used as queries
+
+DiffJSON compares two json values key by key and reports added ,removed
+and modified paths
+*/
+
+// JSONType : Type of JSON value
+type JSONType int
+
+const (
+	JSONNull JSONType = iota
+	JSONBool
+	JSONNumber
+	JSONString
+	JSONArray
+	JSONObject
+)
+
+// JSONTypeString : Name of json type
+func JSONTypeString(z JSONType) string {
+	switch z {
+	case JSONNull:
+		return "null"
+	case JSONBool:
+		return "boolean"
+	case JSONNumber:
+		return "number"
+	case JSONString:
+		return "string"
+	case JSONArray:
+		return "array"
+	case JSONObject:
+		return "object"
+	default:
+		return "invalid"
+	}
+}
+
+// JSONTypeOf : Type of decoded json value
+func JSONTypeOf(v interface{}) JSONType {
+	switch v.(type) {
+	case bool:
+		return JSONBool
+	case json.Number, float64, float32, int, int64:
+		return JSONNumber
+	case string:
+		return JSONString
+	case []interface{}:
+		return JSONArray
+	case map[string]interface{}:
+		return JSONObject
+	default:
+		return JSONNull
+	}
+}
+
+// JSONResult : Result of a json query
+type JSONResult struct {
+	Path   string      // Query used
+	Value  interface{} // Decoded value (numbers are json.Number)
+	Exists bool
+}
+
+// ParseJSON : Parse json (numbers are stored as json.Number)
+func ParseJSON(bin []byte) (interface{}, error) {
+	var v interface{}
+	dec := json.NewDecoder(bytes.NewReader(bin))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return nil, fmt.Errorf("failed to parse json %v", err)
+	}
+	return v, nil
+}
+
+// ParseJSON : Parse response body as json
+func (r *RawHttpResponse) ParseJSON() (interface{}, error) {
+	return ParseJSON(r.Body)
+}
+
+// JSON : Query json body of response (result does not exist if body is not json)
+func (r *RawHttpResponse) JSON(query string) JSONResult {
+	v, err := r.ParseJSON()
+	if err != nil {
+		return JSONResult{Path: query}
+	}
+	return QueryJSON(v, query)
+}
+
+// JSONSchema : Schema of json body of response
+func (r *RawHttpResponse) JSONSchema() (JSONSchema, error) {
+	v, err := r.ParseJSON()
+	if err != nil {
+		return nil, err
+	}
+	return ExtractJSONSchema(v), nil
+}
+
+// QueryJSON : Query decoded json value
+func QueryJSON(v interface{}, query string) JSONResult {
+	res := JSONResult{Path: query}
+	res.Value, res.Exists = jsonQuery(v, splitQuery(query))
+	return res
+}
+
+// Get : Query relative to this result
+func (j JSONResult) Get(query string) JSONResult {
+	if !j.Exists {
+		return JSONResult{Path: j.Path + "." + query}
+	}
+	res := QueryJSON(j.Value, query)
+	res.Path = j.Path + "." + query
+	return res
+}
+
+// Type : Type of value
+func (j JSONResult) Type() JSONType {
+	return JSONTypeOf(j.Value)
+}
+
+// String : value as string (objects and arrays are json encoded ,empty if missing)
+func (j JSONResult) String() string {
+	if !j.Exists {
+		return ""
+	}
+	return jsonString(j.Value)
+}
+
+// Raw : json encoded value
+func (j JSONResult) Raw() string {
+	if !j.Exists {
+		return ""
+	}
+	bin, _ := json.Marshal(j.Value)
+	return string(bin)
+}
+
+// Int : value as integer (0 if not a number)
+func (j JSONResult) Int() int64 {
+	switch t := j.Value.(type) {
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		f, _ := t.Float64()
+		return int64(f)
+	case string:
+		i, _ := strconv.ParseInt(t, 10, 64)
+		return i
+	case bool:
+		if t {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Float : value as float (0 if not a number)
+func (j JSONResult) Float() float64 {
+	switch t := j.Value.(type) {
+	case json.Number:
+		f, _ := t.Float64()
+		return f
+	case string:
+		f, _ := strconv.ParseFloat(t, 64)
+		return f
+	}
+	return 0
+}
+
+// Bool : value as bool (true ,"true" ,"1" and non-zero numbers are true)
+func (j JSONResult) Bool() bool {
+	switch t := j.Value.(type) {
+	case bool:
+		return t
+	case string:
+		b, _ := strconv.ParseBool(t)
+		return b
+	case json.Number:
+		f, _ := t.Float64()
+		return f != 0
+	}
+	return false
+}
+
+// Array : elements of array (single element if value is not an array)
+func (j JSONResult) Array() []JSONResult {
+	if !j.Exists {
+		return []JSONResult{}
+	}
+	arr, ok := j.Value.([]interface{})
+	if !ok {
+		return []JSONResult{j}
+	}
+	res := make([]JSONResult, len(arr))
+	for i, v := range arr {
+		res[i] = JSONResult{Path: j.Path + "." + strconv.Itoa(i), Value: v, Exists: true}
+	}
+	return res
+}
+
+// Map : fields of object (empty if value is not an object)
+func (j JSONResult) Map() map[string]JSONResult {
+	res := map[string]JSONResult{}
+	if obj, ok := j.Value.(map[string]interface{}); ok {
+		for k, v := range obj {
+			res[k] = JSONResult{Path: j.Path + "." + k, Value: v, Exists: true}
+		}
+	}
+	return res
+}
+
+/* Query Evaluation */
+
+// splitQuery : split query into components (JSONPath is converted to dot notation)
+func splitQuery(query string) []string {
+	query = strings.TrimSpace(query)
+	if query == "$" || strings.HasPrefix(query, "$.") || strings.HasPrefix(query, "$[") {
+		query = strings.TrimPrefix(query[1:], ".")
+	}
+
+	parts := []string{}
+	var cur strings.Builder
+	depth := 0
+	flush := func() {
+		if cur.Len() > 0 {
+			parts = append(parts, cur.String())
+			cur.Reset()
+		}
+	}
+
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		switch {
+		case c == '\\' && i+1 < len(query) && depth == 0:
+			i++
+			cur.WriteByte(query[i])
+		case c == '(':
+			depth++
+			cur.WriteByte(c)
+		case c == ')':
+			depth--
+			cur.WriteByte(c)
+		case depth > 0:
+			cur.WriteByte(c)
+		case c == '.':
+			flush()
+		case c == '[':
+			// JSONPath [0] ,[*] ,['key']
+			end := strings.IndexByte(query[i:], ']')
+			if end < 0 {
+				cur.WriteByte(c)
+				continue
+			}
+			flush()
+			inner := strings.Trim(query[i+1:i+end], `'"`)
+			parts = append(parts, inner)
+			i += end
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	flush()
+
+	return parts
+}
+
+func jsonQuery(v interface{}, parts []string) (interface{}, bool) {
+	if len(parts) == 0 {
+		return v, true
+	}
+	part, rest := parts[0], parts[1:]
+
+	switch {
+	case part == "#":
+		arr, ok := v.([]interface{})
+		if !ok {
+			if obj, ok := v.(map[string]interface{}); ok {
+				// key named #
+				if val, ok := obj[part]; ok {
+					return jsonQuery(val, rest)
+				}
+			}
+			return nil, false
+		}
+		if len(rest) == 0 {
+			return json.Number(strconv.Itoa(len(arr))), true
+		}
+		return collect(arr, rest), true
+
+	case part == "*":
+		switch t := v.(type) {
+		case []interface{}:
+			return collect(t, rest), true
+		case map[string]interface{}:
+			values := []interface{}{}
+			for _, k := range sortedKeys(t) {
+				values = append(values, t[k])
+			}
+			return collect(values, rest), true
+		}
+		return nil, false
+
+	case strings.HasPrefix(part, "#(") && (strings.HasSuffix(part, ")") || strings.HasSuffix(part, ")#")):
+		arr, ok := v.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		all := strings.HasSuffix(part, ")#")
+		cond := strings.TrimSuffix(strings.TrimPrefix(part, "#("), "#")
+		cond = strings.TrimSuffix(cond, ")")
+		matched := []interface{}{}
+		for _, e := range arr {
+			if jsonCondition(e, cond) {
+				if !all {
+					return jsonQuery(e, rest)
+				}
+				matched = append(matched, e)
+			}
+		}
+		if !all {
+			return nil, false
+		}
+		return collect(matched, rest), true
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		val, ok := t[part]
+		if !ok {
+			return nil, false
+		}
+		return jsonQuery(val, rest)
+	case []interface{}:
+		i, err := strconv.Atoi(part)
+		if err != nil || i < 0 || i >= len(t) {
+			return nil, false
+		}
+		return jsonQuery(t[i], rest)
+	}
+
+	return nil, false
+}
+
+// collect : query every element and collect existing results
+func collect(arr []interface{}, rest []string) []interface{} {
+	values := []interface{}{}
+	for _, e := range arr {
+		if val, ok := jsonQuery(e, rest); ok {
+			values = append(values, val)
+		}
+	}
+	return values
+}
+
+var conditionOperators = []string{"==", "!=", "<=", ">=", "<", ">", "%"}
+
+// jsonCondition : evaluate condition (ex: role=="admin" ,age>=18 ,name%"adm*")
+// condition without operator checks existence of path
+func jsonCondition(v interface{}, cond string) bool {
+	op, idx := "", -1
+	for _, o := range conditionOperators {
+		if i := strings.Index(cond, o); i >= 0 && (idx < 0 || i < idx) {
+			op, idx = o, i
+		}
+	}
+	if idx < 0 {
+		_, ok := jsonQuery(v, splitQuery(cond))
+		return ok
+	}
+
+	key := strings.TrimSpace(cond[:idx])
+	expected := strings.TrimSpace(cond[idx+len(op):])
+
+	var actual interface{} = v
+	if key != "" {
+		var ok bool
+		if actual, ok = jsonQuery(v, splitQuery(key)); !ok {
+			return false
+		}
+	}
+
+	// expected value is json literal (string ,number ,bool ,null)
+	var want interface{}
+	if w, err := ParseJSON([]byte(expected)); err == nil {
+		want = w
+	} else {
+		want = expected
+	}
+
+	if op == "%" {
+		pattern, _ := want.(string)
+		matched, _ := path.Match(pattern, jsonString(actual))
+		return matched
+	}
+
+	cmp, comparable := compareJSON(actual, want)
+	switch op {
+	case "==":
+		return comparable && cmp == 0
+	case "!=":
+		return !comparable || cmp != 0
+	case "<":
+		return comparable && cmp < 0
+	case "<=":
+		return comparable && cmp <= 0
+	case ">":
+		return comparable && cmp > 0
+	case ">=":
+		return comparable && cmp >= 0
+	}
+	return false
+}
+
+// compareJSON : compare numbers numerically and others as strings
+func compareJSON(a, b interface{}) (int, bool) {
+	an, aok := a.(json.Number)
+	bn, bok := b.(json.Number)
+	if aok && bok {
+		af, _ := an.Float64()
+		bf, _ := bn.Float64()
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		}
+		return 0, true
+	}
+	if JSONTypeOf(a) != JSONTypeOf(b) {
+		return 0, false
+	}
+	return strings.Compare(jsonString(a), jsonString(b)), true
+}
+
+/* Schema */
+
+// JSONField : Key path and type(s) of a value
+type JSONField struct {
+	Path  string     // Key Path (array indices are #)
+	Types []JSONType // Types of value (multiple if elements of array have different types)
+}
+
+// JSONSchema : Fields of json value (sorted by path)
+type JSONSchema []JSONField
+
+// ExtractJSONSchema : Extract key paths and types from decoded json
+func ExtractJSONSchema(v interface{}) JSONSchema {
+	types := map[string]map[JSONType]bool{}
+	schemaWalk(v, "", types)
+
+	schema := JSONSchema{}
+	for p, t := range types {
+		f := JSONField{Path: p}
+		for k := range t {
+			f.Types = append(f.Types, k)
+		}
+		sort.Slice(f.Types, func(i, j int) bool { return f.Types[i] < f.Types[j] })
+		schema = append(schema, f)
+	}
+	sort.Slice(schema, func(i, j int) bool { return schema[i].Path < schema[j].Path })
+	return schema
+}
+
+func schemaWalk(v interface{}, prefix string, types map[string]map[JSONType]bool) {
+	if prefix != "" {
+		if types[prefix] == nil {
+			types[prefix] = map[JSONType]bool{}
+		}
+		types[prefix][JSONTypeOf(v)] = true
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			schemaWalk(val, joinQuery(prefix, escapeKey(k)), types)
+		}
+	case []interface{}:
+		for _, val := range t {
+			schemaWalk(val, joinQuery(prefix, "#"), types)
+		}
+	}
+}
+
+// Paths : all key paths
+func (s JSONSchema) Paths() []string {
+	paths := make([]string, len(s))
+	for i, f := range s {
+		paths[i] = f.Path
+	}
+	return paths
+}
+
+// Keys : unique key names (sorted) . Useful as parameter wordlist
+func (s JSONSchema) Keys() []string {
+	unique := map[string]bool{}
+	for _, f := range s {
+		parts := splitQuery(f.Path)
+		if len(parts) == 0 {
+			continue
+		}
+		if k := parts[len(parts)-1]; k != "#" {
+			unique[k] = true
+		}
+	}
+	return sortedKeys(unique)
+}
+
+// Field : field with given path
+func (s JSONSchema) Field(path string) (JSONField, bool) {
+	for _, f := range s {
+		if f.Path == path {
+			return f, true
+		}
+	}
+	return JSONField{}, false
+}
+
+/* Diff */
+
+// JSONDiffType : Type of difference
+type JSONDiffType int
+
+const (
+	JSONAdded       JSONDiffType = iota // Key present only in new
+	JSONRemoved                         // Key present only in old
+	JSONModified                        // Value changed
+	JSONTypeChanged                     // Type of value changed
+)
+
+// JSONDiffTypeString : Name of diff type
+func JSONDiffTypeString(z JSONDiffType) string {
+	switch z {
+	case JSONAdded:
+		return "Added"
+	case JSONRemoved:
+		return "Removed"
+	case JSONModified:
+		return "Modified"
+	case JSONTypeChanged:
+		return "TypeChanged"
+	default:
+		return "Invalid"
+	}
+}
+
+// JSONDiff : Difference at a key path
+type JSONDiff struct {
+	Path string // Key Path (array indices are numbers)
+	Type JSONDiffType
+	Old  interface{}
+	New  interface{}
+}
+
+// String : Human readable diff
+func (d JSONDiff) String() string {
+	switch d.Type {
+	case JSONAdded:
+		return fmt.Sprintf("+ %v: %v", d.Path, jsonString(d.New))
+	case JSONRemoved:
+		return fmt.Sprintf("- %v: %v", d.Path, jsonString(d.Old))
+	default:
+		return fmt.Sprintf("~ %v: %v => %v", d.Path, jsonString(d.Old), jsonString(d.New))
+	}
+}
+
+// DiffJSON : Key level diff of two decoded json values (sorted by path)
+// Objects and arrays are compared recursively . Added/Removed subtrees are reported once
+func DiffJSON(old interface{}, new interface{}) []JSONDiff {
+	diffs := []JSONDiff{}
+	diffWalk(old, new, "", &diffs)
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+// DiffJSONKeys : Only added and removed keys (values are ignored)
+func DiffJSONKeys(old interface{}, new interface{}) []JSONDiff {
+	diffs := []JSONDiff{}
+	for _, d := range DiffJSON(old, new) {
+		if d.Type == JSONAdded || d.Type == JSONRemoved {
+			diffs = append(diffs, d)
+		}
+	}
+	return diffs
+}
+
+// JSONDiff : Key level diff of json bodies
+func (r *RawHttpResponse) JSONDiff(other *RawHttpResponse) ([]JSONDiff, error) {
+	old, err := r.ParseJSON()
+	if err != nil {
+		return nil, err
+	}
+	new, err := other.ParseJSON()
+	if err != nil {
+		return nil, err
+	}
+	return DiffJSON(old, new), nil
+}
+
+func diffWalk(old interface{}, new interface{}, prefix string, diffs *[]JSONDiff) {
+	ot, nt := JSONTypeOf(old), JSONTypeOf(new)
+	if ot != nt {
+		*diffs = append(*diffs, JSONDiff{Path: prefix, Type: JSONTypeChanged, Old: old, New: new})
+		return
+	}
+
+	switch o := old.(type) {
+	case map[string]interface{}:
+		n := new.(map[string]interface{})
+		for _, k := range sortedKeys(o) {
+			p := joinQuery(prefix, escapeKey(k))
+			if nv, ok := n[k]; ok {
+				diffWalk(o[k], nv, p, diffs)
+			} else {
+				*diffs = append(*diffs, JSONDiff{Path: p, Type: JSONRemoved, Old: o[k]})
+			}
+		}
+		for _, k := range sortedKeys(n) {
+			if _, ok := o[k]; !ok {
+				*diffs = append(*diffs, JSONDiff{Path: joinQuery(prefix, escapeKey(k)), Type: JSONAdded, New: n[k]})
+			}
+		}
+	case []interface{}:
+		n := new.([]interface{})
+		for i := 0; i < len(o) || i < len(n); i++ {
+			p := joinQuery(prefix, strconv.Itoa(i))
+			switch {
+			case i >= len(n):
+				*diffs = append(*diffs, JSONDiff{Path: p, Type: JSONRemoved, Old: o[i]})
+			case i >= len(o):
+				*diffs = append(*diffs, JSONDiff{Path: p, Type: JSONAdded, New: n[i]})
+			default:
+				diffWalk(o[i], n[i], p, diffs)
+			}
+		}
+	default:
+		if !reflect.DeepEqual(normalizeNumber(old), normalizeNumber(new)) {
+			*diffs = append(*diffs, JSONDiff{Path: prefix, Type: JSONModified, Old: old, New: new})
+		}
+	}
+}
+
+// normalizeNumber : 1.0 and 1 are equal
+func normalizeNumber(v interface{}) interface{} {
+	if n, ok := v.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			return f
+		}
+	}
+	return v
+}
+
+func joinQuery(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// escapeKey : escape special characters of key so that path can be used as query
+func escapeKey(k string) string {
+	r := strings.NewReplacer(`\`, `\\`, ".", `\.`, "[", `\[`, "(", `\(`, ")", `\)`)
+	return r.Replace(k)
+}
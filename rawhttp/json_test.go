@@ -0,0 +1,139 @@
+package rawhttp_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+const jsonBody = `{
+	"user": {"name": "john", "role": "user", "id": 12},
+	"items": [
+		{"id": 1, "name": "apple", "price": 1.5, "tags": ["fruit"]},
+		{"id": 2, "name": "banana", "price": 0.5},
+		{"id": 3, "name": "avocado", "price": 2, "stock": null}
+	],
+	"a.b": true,
+	"$ref": "x"
+}`
+
+func jsonResponse(t *testing.T, body string) *rawhttp.RawHttpResponse {
+	resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n\r\n" + body))
+	if err != nil {
+		t.Fatalf("failed to parse response %v", err)
+	}
+	return resp
+}
+
+func Test_JSON_Query(t *testing.T) {
+	resp := jsonResponse(t, jsonBody)
+
+	cases := map[string]string{
+		"user.name":                   "john",
+		"$.user.id":                   "12",
+		"items.1.name":                "banana",
+		"$.items[2].name":             "avocado",
+		"items.#":                     "3",
+		"items.#.id":                  "[1,2,3]",
+		`items.#(price>1).name`:       "apple",
+		`items.#(price<=1.5)#.name`:   `["apple","banana"]`,
+		`items.#(name=="banana").id`:  "2",
+		`items.#(name%"a*")#.id`:      "[1,3]",
+		`items.#(tags).name`:          "apple",
+		`a\.b`:                        "true",
+		"$ref":                        "x",
+		"items.0.tags.0":              "fruit",
+		"items.2.stock":               "null",
+		`items.#(name=="cherry")#.id`: "[]",
+	}
+
+	for query, expected := range cases {
+		res := resp.JSON(query)
+		if !res.Exists {
+			t.Errorf("%v does not exist", query)
+			continue
+		}
+		if res.String() != expected {
+			t.Errorf("%v: expected %v got %v", query, expected, res.String())
+		}
+	}
+
+	for _, query := range []string{"user.email", "items.5", `items.#(name=="cherry").id`, "user.name.first"} {
+		if resp.JSON(query).Exists {
+			t.Errorf("%v should not exist", query)
+		}
+	}
+
+	if resp.JSON("user.id").Int() != 12 || resp.JSON("items.0.price").Float() != 1.5 || !resp.JSON(`a\.b`).Bool() {
+		t.Errorf("type conversion failed")
+	}
+	if n := len(resp.JSON("items").Array()); n != 3 {
+		t.Errorf("expected 3 elements got %v", n)
+	}
+	if resp.JSON("items").Array()[1].Get("name").String() != "banana" {
+		t.Errorf("relative query failed")
+	}
+	if resp.JSON("user").Type() != rawhttp.JSONObject {
+		t.Errorf("unexpected type %v", rawhttp.JSONTypeString(resp.JSON("user").Type()))
+	}
+}
+
+func Test_JSON_Schema(t *testing.T) {
+	schema, err := jsonResponse(t, jsonBody).JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	price, ok := schema.Field("items.#.price")
+	if !ok || len(price.Types) != 1 || price.Types[0] != rawhttp.JSONNumber {
+		t.Errorf("unexpected field %+v", price)
+	}
+	if f, ok := schema.Field(`a\.b`); !ok || f.Types[0] != rawhttp.JSONBool {
+		t.Errorf("escaped key missing %v", schema.Paths())
+	}
+
+	// schema paths are valid queries
+	resp := jsonResponse(t, jsonBody)
+	for _, p := range schema.Paths() {
+		if !resp.JSON(p).Exists {
+			t.Errorf("schema path %v is not a valid query", p)
+		}
+	}
+
+	keys := strings.Join(schema.Keys(), ",")
+	if keys != "$ref,a.b,id,items,name,price,role,stock,tags,user" {
+		t.Errorf("unexpected keys %v", keys)
+	}
+
+	// mixed types
+	mixed, _ := rawhttp.ParseJSON([]byte(`[{"v": 1}, {"v": "1"}]`))
+	if f, _ := rawhttp.ExtractJSONSchema(mixed).Field("#.v"); len(f.Types) != 2 {
+		t.Errorf("expected two types got %v", f.Types)
+	}
+}
+
+func Test_JSON_Diff(t *testing.T) {
+	old := jsonResponse(t, `{"user": {"name": "john", "id": 1}, "items": [1, 2], "count": 1.0}`)
+	new := jsonResponse(t, `{"user": {"name": "john", "id": "1", "admin": true}, "items": [1], "count": 1}`)
+
+	diffs, err := old.JSONDiff(new)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := []string{}
+	for _, d := range diffs {
+		got = append(got, rawhttp.JSONDiffTypeString(d.Type)+":"+d.Path)
+	}
+	expected := "Removed:items.1,Added:user.admin,TypeChanged:user.id"
+	if strings.Join(got, ",") != expected {
+		t.Errorf("expected %v got %v", expected, strings.Join(got, ","))
+	}
+
+	o, _ := old.ParseJSON()
+	n, _ := new.ParseJSON()
+	if keys := rawhttp.DiffJSONKeys(o, n); len(keys) != 2 {
+		t.Errorf("expected only added and removed keys got %v", keys)
+	}
+}
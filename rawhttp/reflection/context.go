@@ -0,0 +1,256 @@
+package reflection
+
+import (
+	"sort"
+	"strings"
+
+	xhtml "golang.org/x/net/html"
+)
+
+// context : classification of offset in response
+type context struct {
+	location  Location
+	context   Context
+	quote     string
+	tag       string
+	attribute string
+}
+
+// token : span of html token in body
+type token struct {
+	start, end int
+	kind       xhtml.TokenType
+	tag        string // tag name (for tags) or enclosing raw text tag (for text)
+}
+
+var urlAttributes = map[string]bool{
+	"href": true, "src": true, "action": true, "formaction": true, "data": true, "poster": true,
+	"background": true, "cite": true, "srcset": true, "xlink:href": true, "codebase": true, "longdesc": true,
+}
+
+// tokenize : split html into tokens with byte offsets
+func tokenize(body string) []token {
+	tokens := []token{}
+	z := xhtml.NewTokenizer(strings.NewReader(body))
+	offset := 0
+	rawtag := ""
+
+	for {
+		tt := z.Next()
+		if tt == xhtml.ErrorToken {
+			break
+		}
+		raw := z.Raw()
+		t := token{start: offset, end: offset + len(raw), kind: tt}
+		offset += len(raw)
+
+		switch tt {
+		case xhtml.StartTagToken, xhtml.SelfClosingTagToken, xhtml.EndTagToken:
+			name, _ := z.TagName()
+			t.tag = strings.ToLower(string(name))
+			rawtag = ""
+			if tt == xhtml.StartTagToken {
+				switch t.tag {
+				case "script", "style", "textarea", "title", "xmp", "noscript", "iframe", "noembed", "noframes":
+					rawtag = t.tag
+				}
+			}
+		case xhtml.TextToken:
+			t.tag = rawtag
+		}
+		tokens = append(tokens, t)
+	}
+
+	return tokens
+}
+
+// htmlContext : context of offset in html body
+func htmlContext(body string, tokens []token, offset int) context {
+	i := sort.Search(len(tokens), func(i int) bool { return tokens[i].end > offset })
+	if i == len(tokens) {
+		return context{location: BodyLocation, context: HTMLTextContext}
+	}
+	t := tokens[i]
+
+	switch t.kind {
+	case xhtml.CommentToken:
+		return context{location: CommentLocation, context: CommentContext}
+
+	case xhtml.StartTagToken, xhtml.SelfClosingTagToken, xhtml.EndTagToken:
+		c := tagContext(body[t.start:t.end], offset-t.start)
+		c.location = AttributeLocation
+		c.tag = t.tag
+		return c
+
+	case xhtml.TextToken:
+		switch t.tag {
+		case "script":
+			c := jsContext(body[t.start:t.end], offset-t.start)
+			c.location = ScriptLocation
+			c.tag = t.tag
+			return c
+		case "style":
+			return context{location: BodyLocation, context: CSSContext, tag: t.tag}
+		default:
+			return context{location: BodyLocation, context: HTMLTextContext, tag: t.tag}
+		}
+	}
+
+	return context{location: BodyLocation, context: HTMLTextContext}
+}
+
+// tagContext : context of offset inside raw tag (ex: <a href="x" title=y>)
+func tagContext(raw string, offset int) context {
+	c := context{context: TagContext}
+
+	i := 1 // skip <
+	// tag name
+	for i < len(raw) && !isSpace(raw[i]) && raw[i] != '>' && raw[i] != '/' {
+		i++
+	}
+	if offset < i {
+		return c
+	}
+
+	for i < len(raw) {
+		for i < len(raw) && (isSpace(raw[i]) || raw[i] == '/') {
+			i++
+		}
+		if i >= len(raw) || raw[i] == '>' {
+			break
+		}
+
+		// attribute name
+		start := i
+		for i < len(raw) && !isSpace(raw[i]) && raw[i] != '=' && raw[i] != '>' && raw[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(raw[start:i])
+		if offset < i {
+			c.attribute = name
+			return c
+		}
+
+		for i < len(raw) && isSpace(raw[i]) {
+			i++
+		}
+		if i >= len(raw) || raw[i] != '=' {
+			continue
+		}
+		i++
+		for i < len(raw) && isSpace(raw[i]) {
+			i++
+		}
+
+		// attribute value
+		quote := ""
+		if i < len(raw) && (raw[i] == '"' || raw[i] == '\'') {
+			quote = string(raw[i])
+			i++
+		}
+		start = i
+		if quote != "" {
+			end := strings.Index(raw[i:], quote)
+			if end < 0 {
+				end = len(raw) - i
+			}
+			i += end
+		} else {
+			for i < len(raw) && !isSpace(raw[i]) && raw[i] != '>' {
+				i++
+			}
+		}
+
+		if offset >= start && offset <= i {
+			return attributeContext(name, raw[start:i], offset-start, quote)
+		}
+		if quote != "" {
+			i++ // closing quote
+		}
+	}
+
+	return c
+}
+
+// attributeContext : context of offset in attribute value
+func attributeContext(name string, value string, offset int, quote string) context {
+	c := context{context: AttributeContext, quote: quote, attribute: name}
+	switch {
+	case urlAttributes[name]:
+		c.context = URLContext
+	case name == "style":
+		c.context = CSSContext
+	case strings.HasPrefix(name, "on"):
+		// event handler
+		js := jsContext(value, offset)
+		c.context = js.context
+		if js.quote != "" {
+			c.quote = js.quote
+		}
+	}
+	return c
+}
+
+// jsContext : context of offset in javascript source
+func jsContext(src string, offset int) context {
+	c := context{location: BodyLocation, context: JSCodeContext}
+	const (
+		code = iota
+		str
+		linecomment
+		blockcomment
+	)
+	state := code
+	quote := byte(0)
+
+	for i := 0; i < offset && i < len(src); i++ {
+		ch := src[i]
+		switch state {
+		case code:
+			switch {
+			case ch == '"' || ch == '\'' || ch == '`':
+				state, quote = str, ch
+			case ch == '/' && i+1 < len(src) && src[i+1] == '/':
+				state = linecomment
+				i++
+			case ch == '/' && i+1 < len(src) && src[i+1] == '*':
+				state = blockcomment
+				i++
+			}
+		case str:
+			switch ch {
+			case '\\':
+				i++
+			case quote:
+				state = code
+			case '\n':
+				if quote != '`' {
+					// unterminated string
+					state = code
+				}
+			}
+		case linecomment:
+			if ch == '\n' {
+				state = code
+			}
+		case blockcomment:
+			if ch == '*' && i+1 < len(src) && src[i+1] == '/' {
+				state = code
+				i++
+			}
+		}
+	}
+
+	switch state {
+	case str:
+		c.context = JSStringContext
+		c.quote = string(quote)
+	case linecomment, blockcomment:
+		c.context = CommentContext
+	}
+	return c
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
@@ -0,0 +1,347 @@
+package reflection
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+/*
+Reflection Detection (XSS Triage)
+
+Finds values of request inputs (query/body params ,path segments ,cookies
+and headers i.e all rawhttp insertion points) that are reflected in response
+
+For every reflection
+1. Location  => Body ,Header ,Attribute ,Script or Comment
+2. Context   => HTML Text ,Attribute Value (with quote style) ,URL ,JS String (with quote) etc
+3. Unencoded => Special characters of value that were reflected as-is
+
+Encoded reflections are also detected . A special character in value matches
+itself ,HTML entities (&lt; &#60; &#x3c;) ,URL encoding (%3C) ,JS escapes (\x3c \u003c)
+and backslash escapes (\") . Only characters reflected literally are reported as Unencoded
+so value should contain special characters of interest (ex: probe'"<>)
+*/
+
+var (
+	MinValueLength = 4  // Values shorter than this are ignored (too many false positives)
+	SnippetLength  = 40 // Characters of response included on each side of reflection
+)
+
+// SpecialChars : Characters tracked for encoding
+const SpecialChars = "<>\"'`()/\\;={}&"
+
+// Location : Where value was reflected
+type Location int
+
+const (
+	BodyLocation      Location = iota // Body (html text or non-html body)
+	HeaderLocation                    // Response Header
+	AttributeLocation                 // HTML Tag (attribute name or value)
+	ScriptLocation                    // Inside <script> or javascript body
+	CommentLocation                   // HTML Comment
+)
+
+// LocationString : Name of location
+func LocationString(z Location) string {
+	switch z {
+	case BodyLocation:
+		return "Body"
+	case HeaderLocation:
+		return "Header"
+	case AttributeLocation:
+		return "Attribute"
+	case ScriptLocation:
+		return "Script"
+	case CommentLocation:
+		return "Comment"
+	default:
+		return "Invalid"
+	}
+}
+
+// Context : Syntax around reflection
+type Context int
+
+const (
+	RawContext       Context = iota // Non html/js body or header value
+	HTMLTextContext                 // Text between tags (including textarea ,title)
+	TagContext                      // Tag or attribute name
+	AttributeContext                // Attribute value (see Quote)
+	URLContext                      // URL attribute value (href ,src ,action etc) or Location/Refresh header
+	JSStringContext                 // Javascript string literal (see Quote)
+	JSCodeContext                   // Javascript code (outside string)
+	CSSContext                      // Inside <style> or style attribute
+	CommentContext                  // HTML or JS Comment
+)
+
+// ContextString : Name of context
+func ContextString(z Context) string {
+	switch z {
+	case RawContext:
+		return "Raw"
+	case HTMLTextContext:
+		return "HTMLText"
+	case TagContext:
+		return "Tag"
+	case AttributeContext:
+		return "Attribute"
+	case URLContext:
+		return "URL"
+	case JSStringContext:
+		return "JSString"
+	case JSCodeContext:
+		return "JSCode"
+	case CSSContext:
+		return "CSS"
+	case CommentContext:
+		return "Comment"
+	default:
+		return "Invalid"
+	}
+}
+
+// Reflection : Value of request reflected in response
+type Reflection struct {
+	Point     rawhttp.InsertionPoint // Input that was reflected
+	Value     string                 // Reflected text (as present in response)
+	Location  Location
+	Context   Context
+	Quote     string   // Quote enclosing reflection (attribute or js string) . Empty if unquoted
+	Tag       string   // Enclosing tag (lowercase) for html reflections
+	Attribute string   // Attribute name (for attribute reflections)
+	Header    string   // Header name (for header reflections)
+	Offset    int      // Byte offset in body or header value
+	Snippet   string   // Surrounding text of response
+	Unencoded []string // Special characters of value reflected without encoding
+	Encoded   []string // Special characters of value reflected with encoding/escaping
+}
+
+// String : Human readable reflection
+func (r Reflection) String() string {
+	s := fmt.Sprintf("%v reflected in %v (%v", r.Point, LocationString(r.Location), ContextString(r.Context))
+	if r.Quote != "" {
+		s += " " + r.Quote
+	}
+	s += ")"
+	if len(r.Unencoded) > 0 {
+		s += " unencoded: " + strings.Join(r.Unencoded, " ")
+	}
+	return s
+}
+
+// Find : Find reflections of all request inputs in response
+func Find(req *rawhttp.RawHttpRequest, resp *rawhttp.RawHttpResponse) []Reflection {
+	reflections := []Reflection{}
+
+	for _, p := range req.InsertionPoints() {
+		for _, r := range FindValue(p.Value, resp) {
+			r.Point = p
+			reflections = append(reflections, r)
+		}
+	}
+
+	return reflections
+}
+
+// FindValue : Find reflections of value in response body and headers
+func FindValue(value string, resp *rawhttp.RawHttpResponse) []Reflection {
+	reflections := []Reflection{}
+	if len(value) < MinValueLength {
+		return reflections
+	}
+	re, err := regexp.Compile(valueRegex(value))
+	if err != nil {
+		return reflections
+	}
+
+	// headers
+	for _, h := range responseHeaders(resp) {
+		for _, m := range re.FindAllStringIndex(h.value, -1) {
+			r := newReflection(value, h.value, m)
+			r.Location = HeaderLocation
+			r.Context = RawContext
+			r.Header = h.name
+			if h.name == "Location" || h.name == "Refresh" || h.name == "Content-Location" {
+				r.Context = URLContext
+			}
+			reflections = append(reflections, r)
+		}
+	}
+
+	// body
+	body := string(resp.Body)
+	matches := re.FindAllStringIndex(body, -1)
+	if len(matches) == 0 {
+		return reflections
+	}
+
+	var classify func(offset int) context
+	contenttype := strings.ToLower(resp.ContentType)
+	switch {
+	case strings.Contains(contenttype, "javascript") || strings.Contains(contenttype, "ecmascript"):
+		classify = func(offset int) context {
+			c := jsContext(body, offset)
+			c.location = ScriptLocation
+			return c
+		}
+	case strings.Contains(contenttype, "json"):
+		classify = func(offset int) context { return jsContext(body, offset) }
+	case contenttype == "" || strings.Contains(contenttype, "html") || strings.Contains(contenttype, "xml"):
+		tokens := tokenize(body)
+		classify = func(offset int) context { return htmlContext(body, tokens, offset) }
+	default:
+		classify = func(offset int) context { return context{location: BodyLocation, context: RawContext} }
+	}
+
+	for _, m := range matches {
+		r := newReflection(value, body, m)
+		c := classify(m[0])
+		r.Location, r.Context, r.Quote, r.Tag, r.Attribute = c.location, c.context, c.quote, c.tag, c.attribute
+		reflections = append(reflections, r)
+	}
+
+	return reflections
+}
+
+type header struct {
+	name  string
+	value string
+}
+
+// responseHeaders : all headers of response (sorted) including Location and Set-Cookie
+func responseHeaders(resp *rawhttp.RawHttpResponse) []header {
+	headers := []header{}
+	for k, values := range resp.Headers {
+		for _, v := range values {
+			headers = append(headers, header{name: http.CanonicalHeaderKey(k), value: v})
+		}
+	}
+	if resp.Location != "" {
+		headers = append(headers, header{name: "Location", value: resp.Location})
+	}
+	for _, c := range resp.Cookies {
+		headers = append(headers, header{name: "Set-Cookie", value: c.String()})
+	}
+	sort.SliceStable(headers, func(i, j int) bool {
+		if headers[i].name != headers[j].name {
+			return headers[i].name < headers[j].name
+		}
+		return headers[i].value < headers[j].value
+	})
+	return headers
+}
+
+func newReflection(value string, data string, m []int) Reflection {
+	r := Reflection{
+		Value:     data[m[0]:m[1]],
+		Offset:    m[0],
+		Unencoded: []string{},
+		Encoded:   []string{},
+	}
+
+	start := m[0] - SnippetLength
+	if start < 0 {
+		start = 0
+	}
+	end := m[1] + SnippetLength
+	if end > len(data) {
+		end = len(data)
+	}
+	r.Snippet = data[start:end]
+
+	r.Unencoded, r.Encoded = encodingOf(value, r.Value)
+	return r
+}
+
+// encodingOf : special characters of value which were reflected literally or encoded
+func encodingOf(value string, reflected string) ([]string, []string) {
+	unencoded, encoded := []string{}, []string{}
+	seenU, seenE := map[rune]bool{}, map[rune]bool{}
+
+	// walk value and reflected text together . Non special chars are matched literally
+	j := 0
+	for _, c := range value {
+		if j >= len(reflected) {
+			break
+		}
+		if !strings.ContainsRune(SpecialChars, c) {
+			j += len(string(c))
+			continue
+		}
+		loc := charRegex(c).FindStringIndex(reflected[j:])
+		if loc == nil || loc[0] != 0 {
+			break
+		}
+		if loc[1] == len(string(c)) {
+			if !seenU[c] {
+				seenU[c] = true
+				unencoded = append(unencoded, string(c))
+			}
+		} else if !seenE[c] {
+			seenE[c] = true
+			encoded = append(encoded, string(c))
+		}
+		j += loc[1]
+	}
+
+	return unencoded, encoded
+}
+
+var entityNames = map[rune]string{
+	'<': "lt", '>': "gt", '"': "quot", '\'': "apos", '&': "amp", '(': "lpar", ')': "rpar",
+	'=': "equals", ';': "semi", '/': "sol", '\\': "bsol", '`': "grave", '{': "lcub", '}': "rcub",
+}
+
+var charRegexCache = map[rune]*regexp.Regexp{}
+
+func init() {
+	for _, c := range SpecialChars {
+		charRegexCache[c] = regexp.MustCompile("^(?:" + charPattern(c) + ")")
+	}
+}
+
+func charRegex(c rune) *regexp.Regexp {
+	return charRegexCache[c]
+}
+
+// charPattern : regex matching literal ,escaped and encoded forms of special char
+// literal form is tried last so that escaped forms (\") are matched completely
+func charPattern(c rune) string {
+	hex := fmt.Sprintf("%x", c)
+	alternatives := []string{
+		fmt.Sprintf(`(?i:&#0*%d;?)`, c),
+		fmt.Sprintf(`(?i:&#x0*%v;?)`, hex),
+		fmt.Sprintf(`(?i:%%%02x)`, c),
+		fmt.Sprintf(`(?i:%%25%02x)`, c),
+		fmt.Sprintf(`(?i:\\u00%02x)`, c),
+		fmt.Sprintf(`(?i:\\x%02x)`, c),
+	}
+	if name, ok := entityNames[c]; ok {
+		alternatives = append(alternatives, "(?i:&"+name+";)")
+	}
+	if c != '\\' {
+		alternatives = append(alternatives, `\\`+regexp.QuoteMeta(string(c)))
+	} else {
+		alternatives = append(alternatives, `\\\\`)
+	}
+	alternatives = append(alternatives, regexp.QuoteMeta(string(c)))
+	return strings.Join(alternatives, "|")
+}
+
+// valueRegex : regex matching value with special chars in any (encoded) form
+func valueRegex(value string) string {
+	var sb strings.Builder
+	for _, c := range value {
+		if strings.ContainsRune(SpecialChars, c) {
+			sb.WriteString("(?:" + charPattern(c) + ")")
+		} else {
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,131 @@
+package reflection_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	"github.com/tarunKoyalwar/goseclibs/rawhttp/reflection"
+)
+
+func response(t *testing.T, contenttype string, body string, headers ...string) *rawhttp.RawHttpResponse {
+	raw := "HTTP/1.1 200 OK\r\nContent-Type: " + contenttype + "\r\n"
+	for _, h := range headers {
+		raw += h + "\r\n"
+	}
+	raw += "\r\n" + body
+	resp, err := rawhttp.NewRawHttpResponseFromBytes([]byte(raw))
+	if err != nil {
+		t.Fatalf("failed to parse response %v", err)
+	}
+	return resp
+}
+
+func Test_Reflection_Contexts(t *testing.T) {
+	body := `<html><head><title>probe1</title></head><body>
+<p>Hello probe1"'&lt;&gt;</p>
+<a href="/search?q=probe1">link</a>
+<input type=text value='probe1&quot;'>
+<div data-probe1="x"></div>
+<!-- probe1 -->
+<script>
+var a = "probe1\"";
+var b = 'x'; // probe1
+var c = probe1;
+</script>
+<img src=x onerror="track('probe1')">
+<style>.probe1 { color: red }</style>
+</body></html>`
+
+	resp := response(t, "text/html", body)
+	refs := reflection.FindValue(`probe1"'<>`, resp)
+	// values without special chars
+	refs = append(refs, reflection.FindValue("probe1", resp)...)
+
+	type expect struct {
+		location reflection.Location
+		context  reflection.Context
+		quote    string
+	}
+
+	has := func(e expect) *reflection.Reflection {
+		for i, r := range refs {
+			if r.Location == e.location && r.Context == e.context && r.Quote == e.quote {
+				return &refs[i]
+			}
+		}
+		return nil
+	}
+
+	expected := []expect{
+		{reflection.BodyLocation, reflection.HTMLTextContext, ""},
+		{reflection.AttributeLocation, reflection.URLContext, `"`},
+		{reflection.AttributeLocation, reflection.AttributeContext, `'`},
+		{reflection.AttributeLocation, reflection.TagContext, ""},
+		{reflection.CommentLocation, reflection.CommentContext, ""},
+		{reflection.ScriptLocation, reflection.JSStringContext, `"`},
+		{reflection.ScriptLocation, reflection.CommentContext, ""},
+		{reflection.ScriptLocation, reflection.JSCodeContext, ""},
+		{reflection.AttributeLocation, reflection.JSStringContext, "'"},
+		{reflection.BodyLocation, reflection.CSSContext, ""},
+	}
+	for _, e := range expected {
+		if has(e) == nil {
+			t.Errorf("missing reflection in %v %v %v", reflection.LocationString(e.location), reflection.ContextString(e.context), e.quote)
+		}
+	}
+
+	// <p>Hello probe1"'&lt;&gt;</p>
+	r := refs[0]
+	if r.Value != `probe1"'&lt;&gt;` || r.Location != reflection.BodyLocation {
+		t.Fatalf("unexpected first reflection %+v", r)
+	}
+	if strings.Join(r.Unencoded, "") != `"'` || strings.Join(r.Encoded, "") != "<>" {
+		t.Errorf("unexpected encoding unencoded=%v encoded=%v", r.Unencoded, r.Encoded)
+	}
+	if !strings.Contains(r.Snippet, "<p>Hello") {
+		t.Errorf("unexpected snippet %v", r.Snippet)
+	}
+}
+
+func Test_Reflection_Request(t *testing.T) {
+	raw := "GET /profile?name=john%22doe&id=12 HTTP/1.1\r\n" +
+		"Host: example.com\r\n" +
+		"Cookie: theme=darkmode\r\n" +
+		"\r\n"
+	req, err := rawhttp.NewRawHttpRequestFromBytes([]byte(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := response(t, "application/json", `{"name":"john\"doe","id":12}`,
+		"Location: https://example.com/home?theme=darkmode")
+
+	refs := reflection.Find(req, resp)
+
+	var name, theme *reflection.Reflection
+	for i, r := range refs {
+		switch r.Point.Name {
+		case "name":
+			name = &refs[i]
+		case "theme":
+			theme = &refs[i]
+		case "id":
+			t.Errorf("short values should be ignored")
+		}
+	}
+
+	if name == nil || name.Context != reflection.JSStringContext || name.Quote != `"` {
+		t.Fatalf("unexpected reflection %+v", name)
+	}
+	if len(name.Unencoded) != 0 || len(name.Encoded) != 1 || name.Encoded[0] != `"` {
+		t.Errorf("escaped quote should be encoded got %v %v", name.Unencoded, name.Encoded)
+	}
+	if name.Point.Type != rawhttp.QueryInsertion {
+		t.Errorf("unexpected insertion point %v", name.Point)
+	}
+
+	if theme == nil || theme.Location != reflection.HeaderLocation || theme.Header != "Location" || theme.Context != reflection.URLContext {
+		t.Errorf("unexpected reflection %+v", theme)
+	}
+}
@@ -40,6 +40,16 @@ type RawHttpRequest struct {
 	ContentType    string            //Content-type of request
 	Body           string            // Http request body
 	HasBody        bool              // If request body is present
+	Template       *RequestTemplate  // Template if raw request has payload positions (nil otherwise)
+	Scheme         string            // Scheme of request url (Default: https)
+}
+
+// scheme : scheme used while constructing request url
+func (r *RawHttpRequest) scheme() string {
+	if r.Scheme == "" {
+		return "https"
+	}
+	return r.Scheme
 }
 
 // getCookie : Construct Cookie From Data
@@ -57,8 +67,17 @@ func (r *RawHttpRequest) getCookie() string {
 
 }
 
+// GetRequest : Construct *http.Request from raw request (nil if path is not a valid url)
+// use BuildRequest to get the error
 func (r *RawHttpRequest) GetRequest() *http.Request {
+	req, _ := r.BuildRequest()
+	return req
+}
+
+// BuildRequest : Construct *http.Request from raw request
+func (r *RawHttpRequest) BuildRequest() (*http.Request, error) {
 	var req *http.Request
+	var err error
 
 	// update if request body is changed
 	if len(r.Body) > 0 {
@@ -70,18 +89,24 @@ func (r *RawHttpRequest) GetRequest() *http.Request {
 	var url *url.URL
 
 	if r.PredefinedHost != "" {
-		url, _ = url.Parse("https://" + r.PredefinedHost)
+		url, _ = url.Parse(r.scheme() + "://" + r.PredefinedHost)
 	} else {
-		url, _ = url.Parse("https://" + r.Host)
+		url, _ = url.Parse(r.scheme() + "://" + r.Host)
 	}
 
-	z, _ := url.Parse(r.Path)
+	z, err := url.Parse(r.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %v: %v", r.Path, err)
+	}
 	z.RawQuery = r.Params.Encode()
 
 	if r.HasBody {
-		req, _ = http.NewRequest(r.Verb, z.String(), bytes.NewReader([]byte(r.Body)))
+		req, err = http.NewRequest(r.Verb, z.String(), bytes.NewReader([]byte(r.Body)))
 	} else {
-		req, _ = http.NewRequest(r.Verb, z.String(), nil)
+		req, err = http.NewRequest(r.Verb, z.String(), nil)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	req.Host = r.Host
@@ -101,7 +126,7 @@ func (r *RawHttpRequest) GetRequest() *http.Request {
 		req.Header.Set("Content-Type", r.ContentType)
 	}
 
-	return req
+	return req, nil
 
 }
 
@@ -165,12 +190,15 @@ func (r *RawHttpRequest) Parse(dat string) error {
 			var url *url.URL
 
 			if r.PredefinedHost != "" {
-				url, _ = url.Parse("https://" + r.PredefinedHost)
+				url, _ = url.Parse(r.scheme() + "://" + r.PredefinedHost)
 			} else {
-				url, _ = url.Parse("https://" + r.Host)
+				url, _ = url.Parse(r.scheme() + "://" + r.Host)
 			}
 
-			z, _ := url.Parse(r.Path)
+			z, err := url.Parse(r.Path)
+			if err != nil {
+				return fmt.Errorf("invalid path %v: %v", r.Path, err)
+			}
 			r.RawURL = z.String()
 
 			r.Params = z.Query()
@@ -213,7 +241,26 @@ func (r *RawHttpRequest) Parse(dat string) error {
 }
 
 // NewRawHttpRequest : New Raw Http Request From string
+// If raw request has payload positions (§default§ or {{FUZZ}}) it is parsed
+// into Template and returned request is generated using default values
+// (see NewRequestTemplate)
 func NewRawHttpRequest(dat string) (*RawHttpRequest, error) {
+	if HasPositions(dat) {
+		if t, err := NewRequestTemplate(dat); err == nil {
+			r, err := t.Baseline()
+			if err == nil {
+				r.Template = t
+			}
+			return r, err
+		}
+		// unbalanced markers are treated as part of request
+	}
+
+	return parseRawHttpRequest(dat)
+}
+
+// parseRawHttpRequest : parse raw request without looking for positions
+func parseRawHttpRequest(dat string) (*RawHttpRequest, error) {
 	r := RawHttpRequest{}
 	er := r.Parse(dat)
 
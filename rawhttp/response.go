@@ -2,7 +2,9 @@ package rawhttp
 
 import (
 	"bytes"
+	"compress/flate"
 	"compress/gzip"
+	"compress/zlib"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,13 +12,14 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 /*
 A Wrapper Around http.Response
 Goals
 1. To read raw responses(ex: Burpsuites)
-2. Auto Decode gzip encoded data
+2. Auto Decode gzip/deflate encoded data & chunked responses
 3. Response Comparison
 
 
@@ -39,13 +42,17 @@ var (
 
 type RawHttpResponse struct {
 	Response      *http.Response //http response(Acutal)
+	Proto         string         // Protocol (ex: HTTP/1.1)
 	StatusCode    int
+	Reason        string // Reason Phrase (ex: OK , empty if not sent)
 	ContentLength int
 	ContentType   string
-	Location      string // If response was 302
-	Headers       map[string]string
-	Cookies       map[string]string
+	Location      string                  // If response was 302
+	Headers       http.Header             // Headers with canonical keys (use Headers.Get() for case-insensitive lookup)
+	Cookies       map[string]*http.Cookie // Cookies set by response (including Domain,Path,Expires etc)
+	Trailers      http.Header             // Trailers of chunked response
 	Body          []byte
+	Duration      time.Duration // Time taken to receive response (set by SHTTPClient.DoRaw ,zero if unknown)
 }
 
 func NewRawHttpResponse(res *http.Response) (*RawHttpResponse, error) {
@@ -66,20 +73,22 @@ func NewRawHttpResponseFromBytes(bin []byte) (*RawHttpResponse, error) {
 
 func (r *RawHttpResponse) Parse(resp *http.Response) error {
 
-	r.Headers = map[string]string{}
-	r.Cookies = map[string]string{}
+	r.Headers = http.Header{}
+	r.Cookies = map[string]*http.Cookie{}
 
 	if StoreResponse {
 		r.Response = resp // Just a reference
 	}
 
+	r.Proto = resp.Proto
 	r.StatusCode = resp.StatusCode
+	r.Reason = strings.TrimSpace(strings.TrimPrefix(resp.Status, strconv.Itoa(resp.StatusCode)))
 
 	setcookies := resp.Cookies()
 
 	if len(setcookies) > 0 {
 		for _, v := range setcookies {
-			r.Cookies[v.Name] = v.Value
+			r.Cookies[v.Name] = v
 		}
 	}
 
@@ -107,11 +116,11 @@ func (r *RawHttpResponse) Parse(resp *http.Response) error {
 	}
 
 	for k, v := range resp.Header {
-		// Ignore Cookie Headers
-		if k == "Set-Cookie" || k == "Location" {
+		// Ignore Cookie Headers (Content-Length is calculated from body)
+		if k == "Set-Cookie" || k == "Location" || k == "Content-Length" {
 			continue
 		} else {
-			r.Headers[k] = strings.Join(v, " ")
+			r.Headers[k] = append([]string{}, v...)
 		}
 
 		if k == "Content-Type" {
@@ -119,26 +128,15 @@ func (r *RawHttpResponse) Parse(resp *http.Response) error {
 			r.ContentType = strings.ToLower(r.ContentType)
 		}
 
-		// If content-encoding is gzip
+		// If content-encoding is gzip/deflate
 		if k == "Content-Encoding" && StoreResponseBody {
-
-			val := strings.Join(v, " ")
-
-			// Only Supports gzip for now
-			if strings.Contains(val, "gzip") {
-				rdr, gerr := gzip.NewReader(bytes.NewReader(r.Body))
-				if gerr == nil {
-					// if successfully decoded
-					bin, derr := ioutil.ReadAll(rdr)
-					if derr == nil {
-						r.Body = bin
-					}
-				}
-			}
-
+			r.Body = decodeContent(r.Body, strings.Join(v, " "))
 		}
 	}
 
+	// Trailers are available only after body is read
+	r.Trailers = resp.Trailer.Clone()
+
 	// Prettify JSON Body if it is json
 	if StoreResponseBody && strings.Contains(r.ContentType, "json") {
 		r.Body = PrettyJSON(r.Body)
@@ -166,43 +164,49 @@ func (r *RawHttpResponse) modifystatuscode() {
 }
 
 // ParseFromBytes : Parse response from bytes (ex: burp response)
+// Header names are canonicalized (same as Parse) irrespective of case sent by server
+// Chunked bodies are de-chunked and Content-Encoding is decoded (same as Parse)
 func (r *RawHttpResponse) ParseFromBytes(bin []byte) error {
 
 	//Initialize maps
-	r.Cookies = map[string]string{}
-	r.Headers = map[string]string{}
+	r.Cookies = map[string]*http.Cookie{}
+	r.Headers = http.Header{}
+	r.Trailers = nil
 
-	// Remove all \r
-	temp := bytes.ReplaceAll(bin, []byte{'\r'}, []byte{})
+	// Set-Cookie headers are parsed at end
+	setcookies := http.Header{}
 
-	// split data at \n\n i.e response headers and body
-	data := bytes.SplitN(temp, []byte{'\n', '\n'}, 2)
+	// split data at empty line i.e response headers and body
+	// body is not modified since it may be binary (ex: gzip)
+	raw, body := splitHeadersAndBody(bin)
 
-	if len(data) == 1 {
-		// Response does not have any body
-		r.ContentLength = 0
-		r.Body = []byte{}
-	} else {
-		r.Body = data[1]
-		r.ContentLength = len(data[1])
-	}
+	r.Body = body
+	r.ContentLength = len(body)
 
 	// raw contains upper body of raw response
 	// which contains all headers,cookies , status code etc
-	raw := data[0]
+	raw = bytes.ReplaceAll(raw, []byte{'\r'}, []byte{})
+
+	chunked := false
+	encoding := ""
 
 	for k, v := range Split(string(raw), '\n') {
 		if k == 0 {
-			//First line extract status code
-			line := strings.SplitN(v, " ", 3)
-			if len(line) != 3 {
+			//First line extract protocol, status code and reason (optional)
+			line := strings.SplitN(strings.TrimSpace(v), " ", 3)
+			if len(line) < 2 || !strings.HasPrefix(line[0], "HTTP/") {
 				return fmt.Errorf("malformed response received %v", v)
 			}
 			val, err := strconv.Atoi(line[1])
 			if err != nil {
 				return fmt.Errorf("failed to parse status code %v", line[1])
 			}
+			r.Proto = line[0]
 			r.StatusCode = val
+			r.Reason = ""
+			if len(line) == 3 {
+				r.Reason = strings.TrimSpace(line[2])
+			}
 		} else {
 			// All remaining items are headers
 			line := strings.SplitN(v, ":", 2)
@@ -210,35 +214,58 @@ func (r *RawHttpResponse) ParseFromBytes(bin []byte) error {
 				//malformed skip this
 				continue
 			} else {
-				key := line[0]
+				// same canonical form as net/http so that responses
+				// from both Parse() and ParseFromBytes() are comparable
+				key := http.CanonicalHeaderKey(strings.TrimSpace(line[0]))
 				value := strings.TrimSpace(line[1])
 
 				switch key {
 				case "Content-Length":
 					continue
+				case "Transfer-Encoding":
+					// removed after de-chunking (same as net/http)
+					if strings.Contains(strings.ToLower(value), "chunked") {
+						chunked = true
+						continue
+					}
+					r.Headers[key] = append(r.Headers[key], value)
 				case "Content-Type":
-					r.ContentType = value
+					r.ContentType = strings.ToLower(value)
+					r.Headers[key] = append(r.Headers[key], value)
+				case "Content-Encoding":
+					encoding = value
+					r.Headers[key] = append(r.Headers[key], value)
 				case "Location":
 					r.Location = value
 				case "Set-Cookie":
-					//only set cookie and its value
-					tarr := strings.Split(value, ";")
-					if len(tarr) > 0 {
-						d := strings.SplitN(tarr[0], "=", 2)
-						if len(d) == 2 {
-							cookiename := strings.TrimSpace(d[0])
-							r.Cookies[cookiename] = d[1]
-						}
-					}
+					setcookies.Add(key, value)
 				default:
-					//treat as header
-					r.Headers[key] = value
+					//treat as header (repeated headers are not joined)
+					r.Headers[key] = append(r.Headers[key], value)
 				}
 			}
 
 		}
 	}
 
+	if chunked {
+		dechunked, trailers, err := dechunk(r.Body)
+		if err == nil {
+			r.Body = dechunked
+			r.ContentLength = len(dechunked)
+			r.Trailers = trailers
+		}
+	}
+
+	if encoding != "" {
+		r.Body = decodeContent(r.Body, encoding)
+	}
+
+	// Same parser as net/http (includes all cookie attributes)
+	for _, v := range (&http.Response{Header: setcookies}).Cookies() {
+		r.Cookies[v.Name] = v
+	}
+
 	if strings.Contains(r.ContentType, "json") && len(r.Body) > 2 {
 		r.Body = PrettyJSON(r.Body)
 	}
@@ -249,6 +276,121 @@ func (r *RawHttpResponse) ParseFromBytes(bin []byte) error {
 
 }
 
+// splitHeadersAndBody : split raw response at first empty line (\r\n\r\n or \n\n)
+func splitHeadersAndBody(bin []byte) ([]byte, []byte) {
+	crlf := bytes.Index(bin, []byte("\r\n\r\n"))
+	lf := bytes.Index(bin, []byte("\n\n"))
+
+	switch {
+	case crlf >= 0 && (lf < 0 || crlf < lf):
+		return bin[:crlf], bin[crlf+4:]
+	case lf >= 0:
+		return bin[:lf], bin[lf+2:]
+	}
+
+	// Response does not have any body
+	return bin, []byte{}
+}
+
+// dechunk : decode chunked transfer encoding and return body with trailers
+// line endings can be \r\n or \n (some exports normalize line endings)
+func dechunk(bin []byte) ([]byte, http.Header, error) {
+	var body bytes.Buffer
+	rest := bin
+
+	readline := func() (string, error) {
+		i := bytes.IndexByte(rest, '\n')
+		if i < 0 {
+			line := string(rest)
+			rest = nil
+			return strings.TrimRight(line, "\r"), io.EOF
+		}
+		line := string(rest[:i])
+		rest = rest[i+1:]
+		return strings.TrimRight(line, "\r"), nil
+	}
+
+	for {
+		line, err := readline()
+		if err != nil && line == "" {
+			return nil, nil, fmt.Errorf("unexpected end of chunked body")
+		}
+		// ignore chunk extensions
+		if i := strings.Index(line, ";"); i >= 0 {
+			line = line[:i]
+		}
+		size, perr := strconv.ParseInt(strings.TrimSpace(line), 16, 64)
+		if perr != nil || size < 0 {
+			return nil, nil, fmt.Errorf("invalid chunk size %v", line)
+		}
+		if size == 0 {
+			break
+		}
+		if int64(len(rest)) < size {
+			return nil, nil, fmt.Errorf("chunk size %v exceeds body", size)
+		}
+		body.Write(rest[:size])
+		rest = rest[size:]
+		// skip line ending after chunk data
+		if bytes.HasPrefix(rest, []byte("\r\n")) {
+			rest = rest[2:]
+		} else if bytes.HasPrefix(rest, []byte("\n")) {
+			rest = rest[1:]
+		}
+	}
+
+	// trailers are terminated by empty line
+	var trailers http.Header
+	for len(rest) > 0 {
+		line, _ := readline()
+		if line == "" {
+			break
+		}
+		kv := strings.SplitN(line, ":", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		if trailers == nil {
+			trailers = http.Header{}
+		}
+		trailers.Add(strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1]))
+	}
+
+	return body.Bytes(), trailers, nil
+}
+
+// decodeContent : decode body using content encoding (gzip & deflate)
+// body is returned as is if decoding fails or encoding is not supported
+func decodeContent(bin []byte, encoding string) []byte {
+	encoding = strings.ToLower(encoding)
+
+	var rdr io.Reader
+	switch {
+	case strings.Contains(encoding, "gzip"):
+		gr, err := gzip.NewReader(bytes.NewReader(bin))
+		if err != nil {
+			return bin
+		}
+		rdr = gr
+	case strings.Contains(encoding, "deflate"):
+		// deflate is usually sent with zlib wrapper
+		zr, err := zlib.NewReader(bytes.NewReader(bin))
+		if err != nil {
+			rdr = flate.NewReader(bytes.NewReader(bin))
+		} else {
+			rdr = zr
+		}
+	default:
+		return bin
+	}
+
+	decoded, err := ioutil.ReadAll(rdr)
+	if err != nil {
+		return bin
+	}
+	return decoded
+}
+
 // PrettyJSON : Format/Indent JSON
 func PrettyJSON(bin []byte) []byte {
 	var prettyJSON bytes.Buffer
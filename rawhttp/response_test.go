@@ -1,6 +1,8 @@
 package rawhttp_test
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
 	"log"
 	"net/http"
@@ -75,3 +77,99 @@ func Test_Response_From_Bytes(t *testing.T) {
 		t.Logf("Something Went Wrong Status Code is 0")
 	}
 }
+
+func Test_Response_MultiValues(t *testing.T) {
+	raw := "HTTP/1.1 200 OK\r\n" +
+		"Content-Type: text/html\r\n" +
+		"Link: </a.css>; rel=preload\r\n" +
+		"Link: </b.js>; rel=preload\r\n" +
+		"Set-Cookie: session=abc; Path=/; Domain=example.com; Secure; HttpOnly; SameSite=Strict\r\n" +
+		"Set-Cookie: theme=dark; Max-Age=3600\r\n" +
+		"\r\n" +
+		"<html></html>"
+
+	r, err := rawhttp.NewRawHttpResponseFromBytes([]byte(raw))
+	if err != nil {
+		t.Fatalf("Failed to parse response %v", err)
+	}
+
+	if links := r.Headers["Link"]; len(links) != 2 || links[1] != "</b.js>; rel=preload" {
+		t.Errorf("repeated headers must not be joined got %v", links)
+	}
+
+	session, ok := r.Cookies["session"]
+	if !ok {
+		t.Fatalf("session cookie missing %v", r.Cookies)
+	}
+	if session.Value != "abc" || session.Path != "/" || session.Domain != "example.com" ||
+		!session.Secure || !session.HttpOnly || session.SameSite != http.SameSiteStrictMode {
+		t.Errorf("cookie attributes missing %+v", session)
+	}
+
+	if theme := r.Cookies["theme"]; theme == nil || theme.MaxAge != 3600 || theme.Secure {
+		t.Errorf("unexpected cookie %+v", theme)
+	}
+}
+
+func Test_Response_Canonical_Headers(t *testing.T) {
+	raw := "HTTP/2 302 Found\ncontent-type: text/html\nlocation: /login\nx-request-id: 1\nset-cookie: a=b\n\n"
+
+	r, err := rawhttp.NewRawHttpResponseFromBytes([]byte(raw))
+	if err != nil {
+		t.Fatalf("Failed to parse response %v", err)
+	}
+
+	if _, ok := r.Headers["X-Request-Id"]; !ok {
+		t.Errorf("header keys must be canonical got %v", r.Headers)
+	}
+
+	if r.ContentType != "text/html" || r.Location != "/login" || r.Cookies["a"] == nil {
+		t.Errorf("lowercase special headers not recognized %v %v %v", r.ContentType, r.Location, r.Cookies)
+	}
+}
+
+func Test_Response_Chunked(t *testing.T) {
+	var gz bytes.Buffer
+	w := gzip.NewWriter(&gz)
+	w.Write([]byte("hello world"))
+	w.Close()
+
+	compressed := gz.Bytes()
+
+	raw := []byte("HTTP/1.1 200\r\nTransfer-Encoding: chunked\r\nContent-Encoding: gzip\r\nTrailer: X-Checksum\r\n\r\n")
+	raw = append(raw, []byte(fmt.Sprintf("%x;ext=1\r\n", 5))...)
+	raw = append(raw, compressed[:5]...)
+	raw = append(raw, []byte(fmt.Sprintf("\r\n%x\r\n", len(compressed)-5))...)
+	raw = append(raw, compressed[5:]...)
+	raw = append(raw, []byte("\r\n0\r\nX-Checksum: abc\r\n\r\n")...)
+
+	r, err := rawhttp.NewRawHttpResponseFromBytes(raw)
+	if err != nil {
+		t.Fatalf("Failed to parse response %v", err)
+	}
+
+	if r.Proto != "HTTP/1.1" || r.StatusCode != 200 || r.Reason != "" {
+		t.Errorf("unexpected status line %v %v %v", r.Proto, r.StatusCode, r.Reason)
+	}
+
+	if string(r.Body) != "hello world" {
+		t.Errorf("body not decoded got %q", r.Body)
+	}
+
+	if r.ContentLength != len(compressed) {
+		t.Errorf("content length must be length of de-chunked body got %v", r.ContentLength)
+	}
+
+	if r.Trailers.Get("X-Checksum") != "abc" {
+		t.Errorf("trailers missing %v", r.Trailers)
+	}
+
+	if r.Headers.Get("Transfer-Encoding") != "" {
+		t.Errorf("transfer-encoding must be removed after de-chunking")
+	}
+
+	r2, _ := rawhttp.NewRawHttpResponseFromBytes([]byte("HTTP/1.0 404 Not Found\n\n"))
+	if r2.Proto != "HTTP/1.0" || r2.Reason != "Not Found" {
+		t.Errorf("unexpected status line %v %v", r2.Proto, r2.Reason)
+	}
+}
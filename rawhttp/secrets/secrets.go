@@ -0,0 +1,284 @@
+package secrets
+
+import (
+	_ "embed"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+	"gopkg.in/yaml.v3"
+)
+
+/*
+Rule Based Secret & Sensitive Data Detection
+
+Scans response body and header values for
+1. API Keys & Tokens (AWS ,GCP ,Slack ,Stripe ,GitHub etc)
+2. Private Keys & JWTs
+3. Internal IPs & Emails
+4. Stack Traces & SQL/ORM Errors
+
+Rules are loaded from YAML/JSON (see rules.yaml for format and built-in rules)
+
+Each finding has a confidence between 0 and 1 . For rules with min_entropy
+confidence is calculated using shannon entropy of secret (random looking
+values get higher confidence) . Other rules always have confidence 1
+*/
+
+//go:embed rules.yaml
+var defaultRules []byte
+
+// Location : Part of response where secret was found
+type Location int
+
+const (
+	BodyLocation Location = iota
+	HeaderLocation
+)
+
+// LocationString : Name of location
+func LocationString(z Location) string {
+	switch z {
+	case BodyLocation:
+		return "Body"
+	case HeaderLocation:
+		return "Header"
+	default:
+		return "Invalid"
+	}
+}
+
+// Rule : Secret Detection Rule
+type Rule struct {
+	ID          string   `yaml:"id" json:"id"`
+	Description string   `yaml:"description" json:"description"`
+	Severity    string   `yaml:"severity" json:"severity"`
+	Regex       string   `yaml:"regex" json:"regex"`
+	MinEntropy  float64  `yaml:"min_entropy" json:"min_entropy"`
+	Keywords    []string `yaml:"keywords" json:"keywords"`
+
+	compiled *regexp.Regexp
+}
+
+// Compile : Compile regex of rule
+func (r *Rule) Compile() error {
+	if r.ID == "" {
+		return fmt.Errorf("rule id is missing")
+	}
+	re, err := regexp.Compile(r.Regex)
+	if err != nil {
+		return fmt.Errorf("failed to compile rule %v %v", r.ID, err)
+	}
+	r.compiled = re
+	return nil
+}
+
+// Finding : Secret found in response
+type Finding struct {
+	RuleID      string
+	Description string
+	Severity    string
+	Match       string   // Complete regex match
+	Secret      string   // Secret (first capture group or complete match)
+	Location    Location // Body or Header
+	Header      string   // Header name (if location is header)
+	Offset      int      // Byte Offset of match in body/header value
+	Line        int      // Line number of match in body (starts from 1)
+	Entropy     float64  // Shannon Entropy of secret
+	Confidence  float64  // 0-1
+	Source      string   // URL of response (if known)
+}
+
+// Scanner : Scans responses using rules
+type Scanner struct {
+	Rules []*Rule
+}
+
+// LoadRules : Load rules from YAML or JSON data
+func LoadRules(data []byte) ([]*Rule, error) {
+	rules := []*Rule{}
+	// yaml is a superset of json
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("failed to parse rules %v", err)
+	}
+	for _, r := range rules {
+		if err := r.Compile(); err != nil {
+			return nil, err
+		}
+	}
+	return rules, nil
+}
+
+// LoadRulesFile : Load rules from YAML or JSON file
+func LoadRulesFile(file string) ([]*Rule, error) {
+	bin, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	return LoadRules(bin)
+}
+
+// DefaultRules : Built-in rules
+func DefaultRules() []*Rule {
+	rules, err := LoadRules(defaultRules)
+	if err != nil {
+		// built-in rules are tested
+		panic(err)
+	}
+	return rules
+}
+
+// NewScanner : New scanner with given rules (built-in rules are used if none are given)
+func NewScanner(rules ...*Rule) (*Scanner, error) {
+	if len(rules) == 0 {
+		return &Scanner{Rules: DefaultRules()}, nil
+	}
+	for _, r := range rules {
+		if r.compiled == nil {
+			if err := r.Compile(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return &Scanner{Rules: rules}, nil
+}
+
+// Scan : Scan body and headers
+func (s *Scanner) Scan(body []byte, headers http.Header) []Finding {
+	findings := []Finding{}
+
+	if len(body) > 0 {
+		findings = append(findings, s.scan(string(body), BodyLocation, "")...)
+	}
+
+	keys := make([]string, 0, len(headers))
+	for k := range headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range headers[k] {
+			findings = append(findings, s.scan(v, HeaderLocation, k)...)
+		}
+	}
+
+	return findings
+}
+
+// ScanResponse : Scan response body ,headers and cookies
+func (s *Scanner) ScanResponse(r *rawhttp.RawHttpResponse) []Finding {
+	headers := r.Headers.Clone()
+	if headers == nil {
+		headers = http.Header{}
+	}
+	// Location & Set-Cookie are not part of Headers
+	if r.Location != "" {
+		headers.Set("Location", r.Location)
+	}
+	for _, c := range r.Cookies {
+		headers.Add("Set-Cookie", c.String())
+	}
+
+	findings := s.Scan(r.Body, headers)
+
+	if r.Response != nil && r.Response.Request != nil && r.Response.Request.URL != nil {
+		for i := range findings {
+			findings[i].Source = r.Response.Request.URL.String()
+		}
+	}
+
+	return findings
+}
+
+func (s *Scanner) scan(data string, loc Location, header string) []Finding {
+	findings := []Finding{}
+	lower := ""
+
+	for _, rule := range s.Rules {
+		if rule.compiled == nil {
+			continue
+		}
+
+		if len(rule.Keywords) > 0 {
+			if lower == "" {
+				lower = strings.ToLower(data)
+			}
+			found := false
+			for _, k := range rule.Keywords {
+				if strings.Contains(lower, strings.ToLower(k)) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				continue
+			}
+		}
+
+		for _, m := range rule.compiled.FindAllStringSubmatchIndex(data, -1) {
+			f := Finding{
+				RuleID:      rule.ID,
+				Description: rule.Description,
+				Severity:    rule.Severity,
+				Match:       data[m[0]:m[1]],
+				Location:    loc,
+				Header:      header,
+				Offset:      m[0],
+				Confidence:  1,
+			}
+
+			f.Secret = f.Match
+			if len(m) >= 4 && m[2] >= 0 {
+				f.Secret = data[m[2]:m[3]]
+			}
+
+			f.Entropy = Entropy(f.Secret)
+			if rule.MinEntropy > 0 {
+				if f.Entropy < rule.MinEntropy {
+					// placeholders/examples (ex: xxxxxxxxxx)
+					continue
+				}
+				f.Confidence = confidence(f.Entropy, rule.MinEntropy)
+			}
+
+			if loc == BodyLocation {
+				f.Line = strings.Count(data[:m[0]], "\n") + 1
+			}
+
+			findings = append(findings, f)
+		}
+	}
+
+	return findings
+}
+
+// Entropy : Shannon Entropy (bits per character)
+func Entropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	freq := map[rune]float64{}
+	total := 0.0
+	for _, r := range s {
+		freq[r]++
+		total++
+	}
+	entropy := 0.0
+	for _, c := range freq {
+		p := c / total
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// confidence : scales linearly from 0.5 (at min entropy) to 1 (min entropy + 1.5 bits)
+func confidence(entropy float64, min float64) float64 {
+	c := 0.5 + (entropy-min)/3
+	return math.Min(1, math.Max(0.5, c))
+}
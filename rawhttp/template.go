@@ -0,0 +1,139 @@
+package rawhttp
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+Raw Request Templates (Intruder Style)
+
+Payload positions are marked directly in raw request using
+1. §default§   => burpsuite style markers (value between markers is default value)
+2. {{FUZZ}}    => ffuf style keyword (default value is empty)
+
+Ex:
+GET /api/user?id=§1§ HTTP/1.1
+Host: example.com
+Cookie: session=§abc§
+
+Every marker is a separate position and positions are numbered
+in the order they appear in raw request
+
+NewRawHttpRequest detects positions and returns request generated using
+default values with template in RawHttpRequest.Template . NewRequestTemplate
+can be used directly when raw request must contain positions (returns error otherwise)
+*/
+
+var (
+	TemplateMarker  = "§"        // Marker used to enclose a position
+	TemplateKeyword = "{{FUZZ}}" // Keyword used as position
+)
+
+// TemplatePosition : Payload position in a request template
+type TemplatePosition struct {
+	Index   int    // Index of position
+	Default string // Default value (value between markers)
+}
+
+// RequestTemplate : Raw request with payload positions
+type RequestTemplate struct {
+	Raw       string             // Raw template as given
+	Positions []TemplatePosition // Payload Positions
+	parts     []string           // literal text around positions (len(Positions)+1)
+}
+
+// NewRequestTemplate : Parse raw request with position markers into template
+func NewRequestTemplate(raw string) (*RequestTemplate, error) {
+	t := &RequestTemplate{Raw: raw}
+
+	rest := raw
+
+	for {
+		mi := strings.Index(rest, TemplateMarker)
+		ki := strings.Index(rest, TemplateKeyword)
+
+		if mi < 0 && ki < 0 {
+			break
+		}
+
+		if ki >= 0 && (mi < 0 || ki < mi) {
+			// keyword position
+			t.parts = append(t.parts, rest[:ki])
+			t.Positions = append(t.Positions, TemplatePosition{Index: len(t.Positions)})
+			rest = rest[ki+len(TemplateKeyword):]
+			continue
+		}
+
+		// marker position must be closed
+		end := strings.Index(rest[mi+len(TemplateMarker):], TemplateMarker)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed position marker %v in template", TemplateMarker)
+		}
+		t.parts = append(t.parts, rest[:mi])
+		start := mi + len(TemplateMarker)
+		t.Positions = append(t.Positions, TemplatePosition{
+			Index:   len(t.Positions),
+			Default: rest[start : start+end],
+		})
+		rest = rest[start+end+len(TemplateMarker):]
+	}
+
+	t.parts = append(t.parts, rest)
+
+	if len(t.Positions) == 0 {
+		return nil, fmt.Errorf("no payload positions found in template")
+	}
+
+	// Template with default values must be a valid raw request
+	if _, err := t.Baseline(); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Fill : Raw request after placing given payloads at positions
+func (t *RequestTemplate) Fill(payloads []string) (string, error) {
+	if len(payloads) != len(t.Positions) {
+		return "", fmt.Errorf("template has %v positions but got %v payloads", len(t.Positions), len(payloads))
+	}
+
+	var sb strings.Builder
+	for i, p := range payloads {
+		sb.WriteString(t.parts[i])
+		sb.WriteString(p)
+	}
+	sb.WriteString(t.parts[len(t.parts)-1])
+
+	return sb.String(), nil
+}
+
+// Render : Generate raw request with given payloads
+func (t *RequestTemplate) Render(payloads []string) (*RawHttpRequest, error) {
+	raw, err := t.Fill(payloads)
+	if err != nil {
+		return nil, err
+	}
+	// payloads may contain markers
+	return parseRawHttpRequest(raw)
+}
+
+// HasPositions : Check if raw request contains payload position markers
+func HasPositions(raw string) bool {
+	return strings.Contains(raw, TemplateMarker) || strings.Contains(raw, TemplateKeyword)
+}
+
+// Defaults : default values of all positions
+func (t *RequestTemplate) Defaults() []string {
+	defaults := make([]string, len(t.Positions))
+	for i, p := range t.Positions {
+		defaults[i] = p.Default
+	}
+	return defaults
+}
+
+// Baseline : Request generated using default values
+func (t *RequestTemplate) Baseline() (*RawHttpRequest, error) {
+	return t.Render(t.Defaults())
+}
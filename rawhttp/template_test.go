@@ -0,0 +1,41 @@
+package rawhttp_test
+
+import (
+	"testing"
+
+	"github.com/tarunKoyalwar/goseclibs/rawhttp"
+)
+
+func Test_RequestTemplate(t *testing.T) {
+	req, err := rawhttp.NewRawHttpRequest("GET /api?id=§1§&q={{FUZZ}} HTTP/1.1\nHost: example.com\nCookie: session=§abc§\n\n")
+	if err != nil {
+		t.Fatalf("failed to parse request %v", err)
+	}
+	if req.Template == nil || len(req.Template.Positions) != 3 {
+		t.Fatalf("expected template with 3 positions got %v", req.Template)
+	}
+	// request is generated using default values
+	if req.Params.Get("id") != "1" || req.Params.Get("q") != "" || req.Cookies["session"] != "abc" {
+		t.Errorf("unexpected baseline request %v %v", req.Params, req.Cookies)
+	}
+
+	// payloads containing markers are not parsed again
+	rendered, err := req.Template.Render([]string{"{{FUZZ}}", "§", "x"})
+	if err != nil {
+		t.Fatalf("failed to render template %v", err)
+	}
+	if rendered.Template != nil || rendered.Params.Get("id") != "{{FUZZ}}" || rendered.Params.Get("q") != "§" {
+		t.Errorf("unexpected rendered request %v", rendered.Params)
+	}
+
+	// requests without (or with unbalanced) markers have no template
+	for _, raw := range []string{
+		"GET /api?id=1 HTTP/1.1\nHost: example.com\n\n",
+		"GET /api?price=§5 HTTP/1.1\nHost: example.com\n\n",
+	} {
+		req, err := rawhttp.NewRawHttpRequest(raw)
+		if err != nil || req.Template != nil {
+			t.Errorf("expected plain request for %q got %v %v", raw, req.Template, err)
+		}
+	}
+}